@@ -0,0 +1,284 @@
+// Package generator creates random sudoku puzzles that have a unique
+// solution.
+package generator
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+
+	"mcconachie.co/sudoku/models"
+	"mcconachie.co/sudoku/solver"
+)
+
+// MinClues is the fewest clues that a sudoku with a unique solution can
+// have.
+const MinClues = 17
+
+// ErrClueRange is returned when the requested range of clues is empty, or
+// too small for the puzzle to have a unique solution.
+var ErrClueRange = errors.New("invalid clue range")
+
+// ErrAttempts is returned when Generate gives up after trying the
+// configured number of puzzles.
+var ErrAttempts = errors.New("no matching puzzle within the allowed attempts")
+
+// Options configure a Generator.
+type Options struct {
+	// Seed seeds the random number generator. A Generator with the same
+	// options produces the same sequence of puzzles.
+	Seed int64
+	// MinClues and MaxClues bound the number of clues in each puzzle.
+	// Clues are removed until MinClues is reached, or until no more can
+	// be removed without losing uniqueness. If MinClues is zero, as many
+	// clues as possible are removed; if MaxClues is zero, there is no
+	// upper bound.
+	MinClues int
+	MaxClues int
+
+	// Symmetry keeps the clues of each puzzle symmetric, by removing each
+	// clue along with its images under the symmetry.
+	Symmetry Symmetry
+	// Mask, if not nil, gives the exact squares that hold clues, instead
+	// of removing clues at random. The mask must keep Symmetry, and have a
+	// number of clues within the clue range.
+	Mask *Mask
+
+	// Tiers, if not empty, limits puzzles to the given difficulty tiers.
+	Tiers []solver.Tier
+	// MinRating and MaxRating, if not zero, bound the ER of each puzzle.
+	// A puzzle that cannot be solved by logic alone has no ER, so never
+	// matches a rating range.
+	MinRating float64
+	MaxRating float64
+	// Require, if not empty, only accepts puzzles whose solve log uses the
+	// named technique, e.g. "X-Wing". Names are matched by prefix, so
+	// "Unique Rectangle" matches every type of unique rectangle.
+	Require string
+	// Registry selects the techniques used to rate puzzles. If nil,
+	// models.DefaultRegistry is used.
+	Registry *models.Registry
+
+	// Attempts limits the number of puzzles that each call to Generate
+	// tries before giving up. If zero, there is no limit, other than the
+	// context.
+	Attempts int
+}
+
+// rated reports whether puzzles must be rated to check them against the
+// options.
+func (o Options) rated() bool {
+	return len(o.Tiers) > 0 || o.MinRating != 0 || o.MaxRating != 0 || o.Require != ""
+}
+
+// matches reports whether a solve log meets the difficulty requirements
+// of the options.
+func (o Options) matches(l solver.Log) bool {
+	r := l.Rating()
+	if len(o.Tiers) > 0 && !containsTier(o.Tiers, r.Tier()) {
+		return false
+	}
+	if o.MinRating != 0 || o.MaxRating != 0 {
+		if !r.Solved || r.ER < o.MinRating || (o.MaxRating != 0 && r.ER > o.MaxRating) {
+			return false
+		}
+	}
+	if o.Require != "" {
+		for _, step := range l.Steps {
+			if strings.HasPrefix(step.Technique, o.Require) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func containsTier(tiers []solver.Tier, t solver.Tier) bool {
+	for _, x := range tiers {
+		if x == t {
+			return true
+		}
+	}
+	return false
+}
+
+// A Generator creates random puzzles.
+// A Generator is not safe for concurrent use.
+type Generator struct {
+	opts  Options
+	rng   *rand.Rand
+	count solver.Solver
+	rate  solver.Solver
+}
+
+// New returns a generator with the given options.
+// Returns ErrClueRange if the clue range is invalid, or ErrMask if the
+// mask does not keep the symmetry.
+func New(opts Options) (*Generator, error) {
+	if opts.MaxClues == 0 {
+		opts.MaxClues = 81
+	}
+	if opts.MinClues > opts.MaxClues || opts.MaxClues < MinClues || opts.MaxClues > 81 {
+		return nil, ErrClueRange
+	}
+	if !opts.Symmetry.valid() {
+		return nil, errors.New("unknown symmetry: " + opts.Symmetry.String())
+	}
+	if opts.Mask != nil {
+		if !opts.Mask.Keeps(opts.Symmetry) {
+			return nil, ErrMask
+		}
+		if n := opts.Mask.Clues(); n < opts.MinClues || n > opts.MaxClues || n < MinClues {
+			return nil, ErrClueRange
+		}
+	}
+	return &Generator{
+		opts:  opts,
+		rng:   rand.New(rand.NewSource(opts.Seed)),
+		count: solver.Solver{Engine: solver.DancingLinks},
+		rate:  solver.Solver{Registry: opts.Registry},
+	}, nil
+}
+
+// Generate returns a new puzzle with a unique solution, which meets the
+// configured clue range and difficulty. It keeps trying new puzzles until
+// it finds one, the context is done, or it has made the configured number
+// of attempts.
+func (gen *Generator) Generate(ctx context.Context) (models.Grid, error) {
+	for i := 0; gen.opts.Attempts == 0 || i < gen.opts.Attempts; i++ {
+		if err := ctx.Err(); err != nil {
+			return models.Grid{}, err
+		}
+		var puzzle models.Grid
+		if gen.opts.Mask != nil {
+			puzzle = gen.masked(gen.solution())
+			if !gen.count.CountSolutions(puzzle).Unique() {
+				continue
+			}
+		} else {
+			var err error
+			puzzle, err = gen.dig(ctx, gen.solution())
+			if err != nil {
+				return models.Grid{}, err
+			}
+			if clues(puzzle) > gen.opts.MaxClues {
+				continue
+			}
+		}
+		if ok, err := gen.matches(puzzle); err != nil || ok {
+			return puzzle, err
+		}
+	}
+	return models.Grid{}, ErrAttempts
+}
+
+// matches reports whether the puzzle meets the difficulty requirements.
+func (gen *Generator) matches(puzzle models.Grid) (bool, error) {
+	if !gen.opts.rated() {
+		return true, nil
+	}
+	// the generated puzzles are known to have a unique solution
+	l, err := gen.rate.SolveSteps(puzzle.AssumeUnique())
+	if err != nil && !errors.Is(err, solver.ErrStuck) {
+		return false, err
+	}
+	return gen.opts.matches(l), nil
+}
+
+// solution returns a random complete grid.
+func (gen *Generator) solution() models.Grid {
+	g := models.NewGrid([]byte(strings.Repeat(".", 81)))
+	gen.fill(&g)
+	return g
+}
+
+// fill recursively fills in the grid, trying the values of each square in
+// a random order. Returns false if the grid cannot be completed.
+func (gen *Generator) fill(g *models.Grid) bool {
+	if err := g.Normalize(); err != nil {
+		return false
+	}
+	ix := firstUndefined(*g)
+	if ix < 0 {
+		return true
+	}
+	for _, k := range gen.rng.Perm(9) {
+		if !g.CanSet(ix, k+1) {
+			continue
+		}
+		next := g.Clone()
+		next.Set(ix, k+1)
+		if gen.fill(next) {
+			*g = *next
+			return true
+		}
+	}
+	return false
+}
+
+// dig removes clues from a complete grid in a random order, keeping each
+// removal only if the puzzle still has a unique solution. Each clue is
+// removed along with its images under the symmetry. It stops once
+// MinClues is reached.
+func (gen *Generator) dig(ctx context.Context, solution models.Grid) (models.Grid, error) {
+	puzzle := solution.Clone()
+	n := 81
+	for _, ix := range gen.rng.Perm(81) {
+		if n <= gen.opts.MinClues {
+			break
+		}
+		if err := ctx.Err(); err != nil {
+			return models.Grid{}, err
+		}
+		orbit := gen.opts.Symmetry.orbit(ix)
+		if !puzzle.Get(ix).IsDefined() || n-len(orbit) < gen.opts.MinClues {
+			continue
+		}
+		for _, c := range orbit {
+			puzzle.Set(c, 0)
+		}
+		if gen.count.CountSolutions(*puzzle).Unique() {
+			n -= len(orbit)
+			continue
+		}
+		for _, c := range orbit {
+			puzzle.Set(c, solution.Get(c).Value())
+		}
+	}
+	return *puzzle, nil
+}
+
+// masked keeps the clues of a complete grid that are marked by the mask.
+func (gen *Generator) masked(solution models.Grid) models.Grid {
+	puzzle := solution.Clone()
+	for ix, clue := range gen.opts.Mask {
+		if !clue {
+			puzzle.Set(ix, 0)
+		}
+	}
+	return *puzzle
+}
+
+// clues counts the defined squares of a grid.
+func clues(g models.Grid) int {
+	n := 0
+	for i := 0; i < 81; i++ {
+		if g.Get(i).IsDefined() {
+			n++
+		}
+	}
+	return n
+}
+
+// firstUndefined returns the index of the first square that is not yet
+// defined, or -1 if every square is defined.
+func firstUndefined(g models.Grid) int {
+	for i := 0; i < 81; i++ {
+		if !g.Get(i).IsDefined() {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,150 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcconachie.co/sudoku/models"
+	"mcconachie.co/sudoku/solver"
+)
+
+func TestGenerate(t *testing.T) {
+	tt := []struct {
+		name     string
+		opts     Options
+		min, max int
+	}{
+		{name: "defaults", opts: Options{Seed: 1}, min: MinClues, max: 81},
+		{name: "range", opts: Options{Seed: 2, MinClues: 30, MaxClues: 32}, min: 30, max: 32},
+		{name: "few clues", opts: Options{Seed: 3, MaxClues: 26}, min: MinClues, max: 26},
+	}
+
+	count := solver.Solver{Engine: solver.DancingLinks}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			gen, err := New(tc.opts)
+			require.NoError(t, err)
+			for i := 0; i < 3; i++ {
+				g, err := gen.Generate(context.Background())
+				require.NoError(t, err)
+				n := clues(g)
+				assert.GreaterOrEqual(t, n, tc.min)
+				assert.LessOrEqual(t, n, tc.max)
+				assert.True(t, count.CountSolutions(g).Unique())
+			}
+		})
+	}
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	generate := func(seed int64) string {
+		gen, err := New(Options{Seed: seed})
+		require.NoError(t, err)
+		g, err := gen.Generate(context.Background())
+		require.NoError(t, err)
+		return g.String()
+	}
+	assert.Equal(t, generate(42), generate(42))
+	assert.NotEqual(t, generate(42), generate(43))
+}
+
+func TestGenerateCancelled(t *testing.T) {
+	gen, err := New(Options{})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = gen.Generate(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewClueRange(t *testing.T) {
+	for _, opts := range []Options{
+		{MinClues: 30, MaxClues: 25},
+		{MaxClues: 16},
+		{MaxClues: 82},
+	} {
+		_, err := New(opts)
+		assert.ErrorIs(t, err, ErrClueRange, "%+v", opts)
+	}
+}
+
+func TestGenerateDifficulty(t *testing.T) {
+	tt := []struct {
+		name string
+		opts Options
+	}{
+		{name: "easy", opts: Options{Seed: 1, Tiers: []solver.Tier{solver.Easy}}},
+		{name: "hard", opts: Options{Seed: 2, Tiers: []solver.Tier{solver.Hard}}},
+		{name: "diabolical", opts: Options{Seed: 3, Tiers: []solver.Tier{solver.Diabolical}}},
+		{name: "rating", opts: Options{Seed: 4, MinRating: 2.6, MaxRating: 3.4}},
+		{name: "x-wing", opts: Options{Seed: 5, Require: "X-Wing"}},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			gen, err := New(tc.opts)
+			require.NoError(t, err)
+			g, err := gen.Generate(context.Background())
+			require.NoError(t, err)
+
+			var s solver.Solver
+			l, err := s.SolveSteps(g.AssumeUnique())
+			if !errors.Is(err, solver.ErrStuck) {
+				require.NoError(t, err)
+			}
+			assert.True(t, tc.opts.matches(l), l.Rating().String())
+		})
+	}
+}
+
+func TestGenerateAttempts(t *testing.T) {
+	gen, err := New(Options{Require: "No Such Technique", Attempts: 3})
+	require.NoError(t, err)
+	_, err = gen.Generate(context.Background())
+	assert.ErrorIs(t, err, ErrAttempts)
+}
+
+func TestOptionsMatches(t *testing.T) {
+	var (
+		step = func(technique string, d float64) models.Step {
+			return models.Step{Technique: technique, Difficulty: d}
+		}
+		medium = solver.Log{Steps: []models.Step{step("Hidden Single", 1.2), step("Pointing Pair", 2.6)}, Solved: true}
+		hard   = solver.Log{Steps: []models.Step{step("Hidden Single", 1.2), step("X-Wing", 3.2)}, Solved: true}
+		stuck  = solver.Log{Steps: []models.Step{step("Hidden Single", 1.2)}}
+	)
+	tt := []struct {
+		name string
+		opts Options
+		log  solver.Log
+		want bool
+	}{
+		{"no requirements", Options{}, stuck, true},
+		{"tier", Options{Tiers: []solver.Tier{solver.Medium}}, medium, true},
+		{"wrong tier", Options{Tiers: []solver.Tier{solver.Medium}}, hard, false},
+		{"one of the tiers", Options{Tiers: []solver.Tier{solver.Medium, solver.Hard}}, hard, true},
+		{"unsolved is diabolical", Options{Tiers: []solver.Tier{solver.Diabolical}}, stuck, true},
+		{"rating", Options{MinRating: 3.0, MaxRating: 4.0}, hard, true},
+		{"rating too low", Options{MinRating: 3.0}, medium, false},
+		{"rating too high", Options{MaxRating: 3.0}, hard, false},
+		{"unsolved has no rating", Options{MaxRating: 3.0}, stuck, false},
+		{"technique", Options{Require: "X-Wing"}, hard, true},
+		{"technique prefix", Options{Require: "Pointing"}, medium, true},
+		{"missing technique", Options{Require: "X-Wing"}, medium, false},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, tc.opts.matches(tc.log))
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package generator
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrMask is returned for a mask that cannot be used: one that does not
+// have 81 squares, or that does not keep the configured symmetry.
+var ErrMask = errors.New("invalid mask")
+
+// A Symmetry is a pattern that the clues of a puzzle must keep: a square
+// is a clue if and only if its image under the symmetry is a clue.
+type Symmetry int
+
+const (
+	// NoSymmetry places no constraint on the clues.
+	NoSymmetry Symmetry = iota
+	// Rotational180 keeps the clues the same under a half turn.
+	Rotational180
+	// Rotational90 keeps the clues the same under a quarter turn.
+	Rotational90
+	// Diagonal reflects the clues in the diagonal from r1c1 to r9c9.
+	Diagonal
+	// AntiDiagonal reflects the clues in the diagonal from r1c9 to r9c1.
+	AntiDiagonal
+	// MirrorLeftRight reflects the clues in the middle column.
+	MirrorLeftRight
+	// MirrorTopBottom reflects the clues in the middle row.
+	MirrorTopBottom
+)
+
+// String implements the fmt.Stringer interface
+func (s Symmetry) String() string {
+	switch s {
+	case NoSymmetry:
+		return "none"
+	case Rotational180:
+		return "rotational 180"
+	case Rotational90:
+		return "rotational 90"
+	case Diagonal:
+		return "diagonal"
+	case AntiDiagonal:
+		return "anti-diagonal"
+	case MirrorLeftRight:
+		return "mirror left/right"
+	case MirrorTopBottom:
+		return "mirror top/bottom"
+	default:
+		return "symmetry " + strconv.Itoa(int(s))
+	}
+}
+
+// valid reports whether s is one of the known symmetries.
+func (s Symmetry) valid() bool {
+	return s >= NoSymmetry && s <= MirrorTopBottom
+}
+
+// image returns the square that the symmetry maps square n to.
+func (s Symmetry) image(n int) int {
+	r, c := n/9, n%9
+	switch s {
+	case Rotational180:
+		r, c = 8-r, 8-c
+	case Rotational90:
+		r, c = c, 8-r
+	case Diagonal:
+		r, c = c, r
+	case AntiDiagonal:
+		r, c = 8-c, 8-r
+	case MirrorLeftRight:
+		c = 8 - c
+	case MirrorTopBottom:
+		r = 8 - r
+	}
+	return r*9 + c
+}
+
+// orbit returns square n along with every other square that the symmetry
+// maps it to, when applied repeatedly.
+func (s Symmetry) orbit(n int) []int {
+	cells := []int{n}
+	for m := s.image(n); m != n; m = s.image(m) {
+		cells = append(cells, m)
+	}
+	return cells
+}
+
+// A Mask marks the squares of a puzzle that hold clues.
+type Mask [81]bool
+
+// ParseMask reads a mask, one character per square. The characters '.',
+// '0' and '-' mark an empty square; any other printable character marks a
+// clue. Whitespace is ignored.
+// Returns ErrMask if there are not exactly 81 squares.
+func ParseMask(s string) (Mask, error) {
+	var m Mask
+	i := 0
+	for _, ch := range []byte(s) {
+		if ch <= ' ' {
+			continue
+		}
+		if i == 81 {
+			return Mask{}, ErrMask
+		}
+		m[i] = ch != '.' && ch != '0' && ch != '-'
+		i++
+	}
+	if i != 81 {
+		return Mask{}, ErrMask
+	}
+	return m, nil
+}
+
+// String implements the fmt.Stringer interface, using 'x' for a clue and
+// '.' for an empty square, nine squares to a line.
+func (m Mask) String() string {
+	b := make([]byte, 0, 90)
+	for i, clue := range m {
+		if clue {
+			b = append(b, 'x')
+		} else {
+			b = append(b, '.')
+		}
+		if i%9 == 8 {
+			b = append(b, '\n')
+		}
+	}
+	return string(b)
+}
+
+// Clues counts the clues in the mask.
+func (m Mask) Clues() int {
+	n := 0
+	for _, clue := range m {
+		if clue {
+			n++
+		}
+	}
+	return n
+}
+
+// Keeps reports whether the mask keeps the given symmetry.
+func (m Mask) Keeps(s Symmetry) bool {
+	for n, clue := range m {
+		if m[s.image(n)] != clue {
+			return false
+		}
+	}
+	return true
+}
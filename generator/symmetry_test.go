@@ -0,0 +1,124 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcconachie.co/sudoku/models"
+	"mcconachie.co/sudoku/solver"
+)
+
+// star is a themed mask with quarter turn symmetry.
+const star = `
+	xx..x..xx
+	x...x...x
+	..x...x..
+	...x.x...
+	xx..x..xx
+	...x.x...
+	..x...x..
+	x...x...x
+	xx..x..xx`
+
+func TestSymmetryOrbit(t *testing.T) {
+	tt := []struct {
+		sym  Symmetry
+		n    int
+		want []int
+	}{
+		{NoSymmetry, 10, []int{10}},
+		{Rotational180, 1, []int{1, 79}},
+		{Rotational180, 40, []int{40}},
+		{Rotational90, 1, []int{1, 17, 79, 63}},
+		{Diagonal, 1, []int{1, 9}},
+		{Diagonal, 10, []int{10}},
+		{AntiDiagonal, 0, []int{0, 80}},
+		{AntiDiagonal, 8, []int{8}},
+		{MirrorLeftRight, 0, []int{0, 8}},
+		{MirrorTopBottom, 0, []int{0, 72}},
+	}
+
+	for _, tc := range tt {
+		assert.Equal(t, tc.want, tc.sym.orbit(tc.n), "%s %s", tc.sym, models.CellName(tc.n))
+	}
+}
+
+func TestParseMask(t *testing.T) {
+	a := assert.New(t)
+
+	m, err := ParseMask(star)
+	require.NoError(t, err)
+	a.Equal(29, m.Clues())
+	a.True(m[0])
+	a.False(m[2])
+	a.True(m.Keeps(Rotational90))
+	a.True(m.Keeps(Diagonal))
+	a.Equal("xx..x..xx\n", m.String()[:10])
+
+	m[0] = false
+	a.False(m.Keeps(Rotational180))
+	a.True(m.Keeps(NoSymmetry))
+
+	_, err = ParseMask("x.x")
+	a.ErrorIs(err, ErrMask)
+	_, err = ParseMask(star + "x")
+	a.ErrorIs(err, ErrMask)
+}
+
+func TestGenerateSymmetric(t *testing.T) {
+	count := solver.Solver{Engine: solver.DancingLinks}
+	for sym := Rotational180; sym <= MirrorTopBottom; sym++ {
+		sym := sym
+		t.Run(sym.String(), func(t *testing.T) {
+			t.Parallel()
+			gen, err := New(Options{Seed: int64(sym), Symmetry: sym})
+			require.NoError(t, err)
+			g, err := gen.Generate(context.Background())
+			require.NoError(t, err)
+
+			assert.True(t, maskOf(g).Keeps(sym))
+			assert.True(t, count.CountSolutions(g).Unique())
+		})
+	}
+}
+
+func TestGenerateMask(t *testing.T) {
+	m, err := ParseMask(star)
+	require.NoError(t, err)
+	gen, err := New(Options{Seed: 1, Symmetry: Rotational90, Mask: &m})
+	require.NoError(t, err)
+
+	count := solver.Solver{Engine: solver.DancingLinks}
+	for i := 0; i < 2; i++ {
+		g, err := gen.Generate(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, m, maskOf(g))
+		assert.True(t, count.CountSolutions(g).Unique())
+	}
+}
+
+func TestNewMask(t *testing.T) {
+	m, err := ParseMask(star)
+	require.NoError(t, err)
+
+	_, err = New(Options{Mask: &m, MaxClues: 28})
+	assert.ErrorIs(t, err, ErrClueRange)
+
+	m[0] = false
+	_, err = New(Options{Mask: &m, Symmetry: Rotational180})
+	assert.ErrorIs(t, err, ErrMask)
+
+	_, err = New(Options{Symmetry: Symmetry(99)})
+	assert.Error(t, err)
+}
+
+// maskOf returns the squares of g that are defined.
+func maskOf(g models.Grid) Mask {
+	var m Mask
+	for i := range m {
+		m[i] = g.Get(i).IsDefined()
+	}
+	return m
+}
@@ -0,0 +1,464 @@
+package models
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// An ALS (almost locked set) is a set of n undefined squares within one
+// unit, which between them can hold exactly n+1 values. If any one of
+// those values is removed, the rest are locked into the set.
+type ALS struct {
+	Unit   Unit
+	Cells  []int
+	Digits Square
+}
+
+// String implements the fmt.Stringer interface, e.g. "r1c1,r1c2 {1,2,3}"
+func (a ALS) String() string {
+	return formatCells(a.Cells) + " " + formatDigits(a.Digits)
+}
+
+// An ALSKind identifies one of the almost locked set techniques.
+type ALSKind int
+
+const (
+	// ALSXZ is two almost locked sets A and B joined by a restricted
+	// common value x, which cannot be in both. So one of them is locked,
+	// and any other common value z must be in A or B.
+	ALSXZ ALSKind = iota
+	// ALSXYWing is two almost locked sets A and B, joined to a pivot C by
+	// different restricted common values x and y. One of A and B is
+	// locked, so any value z that they share must be in one of them.
+	ALSXYWing
+	// DeathBlossom is a stem square, and one almost locked set (a petal)
+	// for each of its values, in which every square holding that value
+	// sees the stem. Whatever the stem holds, one petal is locked, so a
+	// value z shared by every petal must be in one of them.
+	DeathBlossom
+)
+
+// String implements the fmt.Stringer interface
+func (k ALSKind) String() string {
+	switch k {
+	case ALSXZ:
+		return "ALS-XZ"
+	case ALSXYWing:
+		return "ALS-XY-Wing"
+	case DeathBlossom:
+		return "Death Blossom"
+	default:
+		return "ALS"
+	}
+}
+
+// An ALSPattern describes an elimination made using almost locked sets.
+type ALSPattern struct {
+	Kind ALSKind
+	// Sets holds A and B for ALS-XZ; A, B and the pivot C for an
+	// ALS-XY-Wing; or a petal for each value of the stem for Death Blossom.
+	Sets []ALS
+	// Restricted holds the restricted common values: one or two between
+	// A and B for ALS-XZ; between A and C, then B and C, for an
+	// ALS-XY-Wing; or the stem value of each petal for Death Blossom.
+	Restricted []int
+	// Stem is the stem square of a Death Blossom.
+	Stem         int
+	Eliminations []Candidate
+}
+
+// Name returns the conventional name of the technique, e.g. "ALS-XZ".
+// ALS-XZ with two restricted common values is "Doubly Linked ALS-XZ".
+func (p ALSPattern) Name() string {
+	if p.Kind == ALSXZ && len(p.Restricted) == 2 {
+		return "Doubly Linked ALS-XZ"
+	}
+	return p.Kind.String()
+}
+
+// String implements the fmt.Stringer interface
+func (p ALSPattern) String() string {
+	var b strings.Builder
+	b.WriteString(p.Name())
+	b.WriteString(": ")
+	switch p.Kind {
+	case DeathBlossom:
+		b.WriteString("stem ")
+		b.WriteString(CellName(p.Stem))
+		for i, s := range p.Sets {
+			b.WriteString(", ")
+			b.WriteString(strconv.Itoa(p.Restricted[i]))
+			b.WriteString(": ")
+			b.WriteString(s.String())
+		}
+	case ALSXYWing:
+		b.WriteString("A=")
+		b.WriteString(p.Sets[0].String())
+		b.WriteString(", B=")
+		b.WriteString(p.Sets[1].String())
+		b.WriteString(", C=")
+		b.WriteString(p.Sets[2].String())
+		b.WriteString(", x=")
+		b.WriteString(strconv.Itoa(p.Restricted[0]))
+		b.WriteString(", y=")
+		b.WriteString(strconv.Itoa(p.Restricted[1]))
+	default:
+		b.WriteString("A=")
+		b.WriteString(p.Sets[0].String())
+		b.WriteString(", B=")
+		b.WriteString(p.Sets[1].String())
+		b.WriteString(", x=")
+		for i, d := range p.Restricted {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Itoa(d))
+		}
+	}
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(p.Eliminations))
+	return b.String()
+}
+
+// A cellSet is a set of squares, as a bit mask over their indices.
+type cellSet [2]uint64
+
+func (s *cellSet) add(n int) {
+	s[n/64] |= 1 << (n % 64)
+}
+
+func (s cellSet) has(n int) bool {
+	return s[n/64]&(1<<(n%64)) != 0
+}
+
+func (s cellSet) and(t cellSet) cellSet {
+	return cellSet{s[0] & t[0], s[1] & t[1]}
+}
+
+func (s cellSet) or(t cellSet) cellSet {
+	return cellSet{s[0] | t[0], s[1] | t[1]}
+}
+
+func (s cellSet) andNot(t cellSet) cellSet {
+	return cellSet{s[0] &^ t[0], s[1] &^ t[1]}
+}
+
+func (s cellSet) empty() bool {
+	return s[0] == 0 && s[1] == 0
+}
+
+// subsetOf reports whether every square in s is also in t.
+func (s cellSet) subsetOf(t cellSet) bool {
+	return s.andNot(t).empty()
+}
+
+// cells returns the squares in the set, in order.
+func (s cellSet) cells() []int {
+	var out []int
+	for i, w := range s {
+		for w != 0 {
+			b := bits.TrailingZeros64(w)
+			out = append(out, i*64+b)
+			w &^= 1 << b
+		}
+	}
+	return out
+}
+
+// als holds an ALS, along with the sets used to find its links.
+type als struct {
+	ALS
+	cells cellSet
+	// with[d] holds the squares of the set that could hold d, and
+	// seenBy[d] holds the squares outside the set that see all of them.
+	with, seenBy [10]cellSet
+}
+
+// almostLockedSets returns every almost locked set in the grid of up to
+// maxSize squares, in order of size. A set that lies in two units (a row
+// or column, and a block) is only returned once, with the row or column as
+// its unit.
+func (g Grid) almostLockedSets(maxSize int) []*als {
+	var (
+		out  []*als
+		seen = make(map[cellSet]bool)
+	)
+	open := make([][]int, 27)
+	for u := range open {
+		open[u] = g.openCells(u)
+	}
+	for size := 1; size <= maxSize && size < 9; size++ {
+		for u := 0; u < 27; u++ {
+			forEachCombination(open[u], size, func(cells []int) bool {
+				digits := none
+				var set cellSet
+				for _, c := range cells {
+					digits |= g.squares[c]
+					set.add(c)
+				}
+				if digits.Count() != size+1 || seen[set] {
+					return true
+				}
+				seen[set] = true
+				a := &als{
+					ALS:   ALS{unitByID(u), append([]int(nil), cells...), digits},
+					cells: set,
+				}
+				for _, d := range digits.Values() {
+					seenBy := cellSet{^uint64(0), ^uint64(0)}
+					for _, c := range cells {
+						if g.squares[c].Has(d) {
+							a.with[d].add(c)
+							seenBy = seenBy.and(peerSets[c])
+						}
+					}
+					a.seenBy[d] = seenBy
+				}
+				out = append(out, a)
+				return true
+			})
+		}
+	}
+	return out
+}
+
+// restrictedCommon returns the restricted common values of a and b: the
+// values they share, where every square in one that could hold the value
+// sees every square in the other that could. Sets that overlap have none.
+func restrictedCommon(a, b *als) Square {
+	if !a.cells.and(b.cells).empty() {
+		return none
+	}
+	rcc := none
+	for _, d := range (a.Digits & b.Digits).Values() {
+		if b.with[d].subsetOf(a.seenBy[d]) {
+			rcc |= squareEnum[d]
+		}
+	}
+	return rcc
+}
+
+// eliminationsSeenBy returns the candidates for digit d in every undefined
+// square outside the given sets, that sees every square in them that
+// could hold d.
+func (g Grid) eliminationsSeenBy(d int, sets ...*als) []Candidate {
+	target := cellSet{^uint64(0), ^uint64(0)}
+	for _, a := range sets {
+		target = target.and(a.seenBy[d]).andNot(a.cells)
+	}
+	var elims []Candidate
+	for _, c := range target.cells() {
+		if c < 81 && g.squares[c].Has(d) && !g.squares[c].IsDefined() {
+			elims = append(elims, Candidate{c, d})
+		}
+	}
+	return elims
+}
+
+// findALSXZ looks for a singly or doubly linked ALS-XZ, with sets of up to
+// maxSize squares, that allows at least one candidate to be eliminated.
+func (g Grid) findALSXZ(maxSize int) (ALSPattern, bool) {
+	sets := g.almostLockedSets(maxSize)
+	for i, a := range sets {
+		for _, b := range sets[i+1:] {
+			rcc := restrictedCommon(a, b)
+			if rcc == none {
+				continue
+			}
+			if rcc.Count() > 2 {
+				// A and B cannot both leave out a restricted common value,
+				// and a third one leaves them a square short between them.
+				// Such a grid has no solution, which Check or the search
+				// will find; no elimination drawn from it means anything.
+				continue
+			}
+			var elims []Candidate
+			for _, z := range (a.Digits & b.Digits &^ rcc).Values() {
+				elims = append(elims, g.eliminationsSeenBy(z, a, b)...)
+			}
+			restricted := rcc.Values()
+			if len(restricted) == 2 {
+				// both sets are locked, apart from one restricted value each
+				for _, x := range restricted {
+					elims = append(elims, g.eliminationsSeenBy(x, a, b)...)
+				}
+				for _, s := range [2]*als{a, b} {
+					for _, d := range (s.Digits &^ rcc).Values() {
+						elims = append(elims, g.eliminationsSeenBy(d, s)...)
+					}
+				}
+				elims = uniqueCandidates(elims)
+			}
+			if len(elims) == 0 {
+				continue
+			}
+			return ALSPattern{
+				Kind:         ALSXZ,
+				Sets:         []ALS{a.ALS, b.ALS},
+				Restricted:   restricted,
+				Eliminations: elims,
+			}, true
+		}
+	}
+	return ALSPattern{}, false
+}
+
+// findALSXYWing looks for an ALS-XY-Wing, with sets of up to maxSize
+// squares, that allows at least one candidate to be eliminated.
+func (g Grid) findALSXYWing(maxSize int) (ALSPattern, bool) {
+	sets := g.almostLockedSets(maxSize)
+	for _, c := range sets {
+		var (
+			linked []*als
+			rccs   []Square
+		)
+		for _, s := range sets {
+			if rcc := restrictedCommon(s, c); rcc != none {
+				linked = append(linked, s)
+				rccs = append(rccs, rcc)
+			}
+		}
+		for i, a := range linked {
+			for j := i + 1; j < len(linked); j++ {
+				b := linked[j]
+				if !a.cells.and(b.cells).empty() {
+					continue
+				}
+				for _, x := range rccs[i].Values() {
+					for _, y := range rccs[j].Values() {
+						if x == y {
+							continue
+						}
+						var elims []Candidate
+						for _, z := range (a.Digits & b.Digits).Values() {
+							if z != x && z != y {
+								elims = append(elims, g.eliminationsSeenBy(z, a, b)...)
+							}
+						}
+						if len(elims) == 0 {
+							continue
+						}
+						return ALSPattern{
+							Kind:         ALSXYWing,
+							Sets:         []ALS{a.ALS, b.ALS, c.ALS},
+							Restricted:   []int{x, y},
+							Eliminations: elims,
+						}, true
+					}
+				}
+			}
+		}
+	}
+	return ALSPattern{}, false
+}
+
+// findDeathBlossom looks for a Death Blossom, with petals of up to maxSize
+// squares, that allows at least one candidate to be eliminated.
+func (g Grid) findDeathBlossom(maxSize int) (ALSPattern, bool) {
+	sets := g.almostLockedSets(maxSize)
+	for stem := 0; stem < 81; stem++ {
+		sq := g.squares[stem]
+		if sq.IsDefined() || sq.Count() < 2 {
+			continue
+		}
+		// petals[i] holds the sets whose squares with the stem's i'th value
+		// all see the stem
+		values := sq.Values()
+		petals := make([][]*als, len(values))
+		for i, d := range values {
+			for _, s := range sets {
+				if s.Digits.Has(d) && !s.cells.has(stem) && s.with[d].subsetOf(peerSets[stem]) {
+					petals[i] = append(petals[i], s)
+				}
+			}
+			if petals[i] == nil {
+				break
+			}
+		}
+		if petals[len(values)-1] == nil {
+			continue
+		}
+		if p, ok := g.deathBlossom(stem, values, petals); ok {
+			return p, true
+		}
+	}
+	return ALSPattern{}, false
+}
+
+// deathBlossom picks one petal for each value of the stem, such that the
+// petals do not overlap, and share a value that is not in the stem.
+func (g Grid) deathBlossom(stem int, values []int, petals [][]*als) (ALSPattern, bool) {
+	chosen := make([]*als, 0, len(values))
+	var (
+		found ALSPattern
+		pick  func(i int, used cellSet, common Square) bool
+	)
+	pick = func(i int, used cellSet, common Square) bool {
+		if i == len(values) {
+			var elims []Candidate
+			for _, z := range common.Values() {
+				for _, e := range g.eliminationsSeenBy(z, chosen...) {
+					if e.Cell != stem {
+						elims = append(elims, e)
+					}
+				}
+			}
+			if len(elims) == 0 {
+				return false
+			}
+			found = ALSPattern{
+				Kind:         DeathBlossom,
+				Restricted:   values,
+				Stem:         stem,
+				Eliminations: elims,
+			}
+			for _, s := range chosen {
+				found.Sets = append(found.Sets, s.ALS)
+			}
+			return true
+		}
+		for _, s := range petals[i] {
+			next := common & s.Digits
+			if next == none || !s.cells.and(used).empty() {
+				continue
+			}
+			chosen = append(chosen, s)
+			if !g.anySeenBy(next, chosen) {
+				// adding more petals can only remove eliminations
+				chosen = chosen[:len(chosen)-1]
+				continue
+			}
+			if pick(i+1, used.or(s.cells), next) {
+				return true
+			}
+			chosen = chosen[:len(chosen)-1]
+		}
+		return false
+	}
+	pick(0, cellSet{}, any&^g.squares[stem])
+	return found, found.Sets != nil
+}
+
+// anySeenBy reports whether, for any of the given values, there is a
+// candidate that eliminationsSeenBy would remove.
+func (g Grid) anySeenBy(values Square, sets []*als) bool {
+	for _, d := range values.Values() {
+		if len(g.eliminationsSeenBy(d, sets...)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueCandidates returns the given candidates without duplicates,
+// keeping the first of each.
+func uniqueCandidates(cs []Candidate) []Candidate {
+	var out []Candidate
+	for _, c := range cs {
+		if !containsCandidate(out, c) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
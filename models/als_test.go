@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindALSPattern(t *testing.T) {
+	tt := []struct {
+		name string
+		grid Grid
+		find func(Grid, int) (ALSPattern, bool)
+		want ALSPattern
+		desc string
+	}{
+		{
+			name: "als-xz",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 4: {1, 3}, 13: {2, 3}}),
+			find: Grid.findALSXZ,
+			want: ALSPattern{
+				Kind: ALSXZ,
+				Sets: []ALS{
+					{Unit{Row, 0}, []int{0}, squareEnum[1] | squareEnum[2]},
+					{Unit{Column, 4}, []int{4, 13}, squareEnum[1] | squareEnum[2] | squareEnum[3]},
+				},
+				Restricted:   []int{1},
+				Eliminations: []Candidate{{3, 2}, {5, 2}, {9, 2}, {10, 2}, {11, 2}},
+			},
+			desc: "ALS-XZ: A=r1c1 {1,2}, B=r1c5,r2c5 {1,2,3}, x=1 => " +
+				"r1c4<>2, r1c6<>2, r2c1<>2, r2c2<>2, r2c3<>2",
+		},
+		{
+			name: "doubly linked als-xz",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 4: {1, 2}}),
+			find: Grid.findALSXZ,
+			want: ALSPattern{
+				Kind: ALSXZ,
+				Sets: []ALS{
+					{Unit{Row, 0}, []int{0}, squareEnum[1] | squareEnum[2]},
+					{Unit{Row, 0}, []int{4}, squareEnum[1] | squareEnum[2]},
+				},
+				Restricted: []int{1, 2},
+				Eliminations: []Candidate{
+					{1, 1}, {2, 1}, {3, 1}, {5, 1}, {6, 1}, {7, 1}, {8, 1},
+					{1, 2}, {2, 2}, {3, 2}, {5, 2}, {6, 2}, {7, 2}, {8, 2},
+				},
+			},
+			desc: "Doubly Linked ALS-XZ: A=r1c1 {1,2}, B=r1c5 {1,2}, x=1,2 => " +
+				"r1c2<>1, r1c3<>1, r1c4<>1, r1c6<>1, r1c7<>1, r1c8<>1, r1c9<>1, " +
+				"r1c2<>2, r1c3<>2, r1c4<>2, r1c6<>2, r1c7<>2, r1c8<>2, r1c9<>2",
+		},
+		{
+			name: "als-xy-wing",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 4: {1, 3}, 36: {2, 3}}),
+			find: Grid.findALSXYWing,
+			want: ALSPattern{
+				Kind: ALSXYWing,
+				Sets: []ALS{
+					{Unit{Row, 0}, []int{4}, squareEnum[1] | squareEnum[3]},
+					{Unit{Row, 4}, []int{36}, squareEnum[2] | squareEnum[3]},
+					{Unit{Row, 0}, []int{0}, squareEnum[1] | squareEnum[2]},
+				},
+				Restricted:   []int{1, 2},
+				Eliminations: []Candidate{{40, 3}},
+			},
+			desc: "ALS-XY-Wing: A=r1c5 {1,3}, B=r5c1 {2,3}, C=r1c1 {1,2}, x=1, y=2 => r5c5<>3",
+		},
+		{
+			name: "death blossom",
+			grid: candidateGrid(map[int][]int{40: {1, 2}, 4: {1, 3}, 36: {2, 3}}),
+			find: Grid.findDeathBlossom,
+			want: ALSPattern{
+				Kind: DeathBlossom,
+				Sets: []ALS{
+					{Unit{Row, 0}, []int{4}, squareEnum[1] | squareEnum[3]},
+					{Unit{Row, 4}, []int{36}, squareEnum[2] | squareEnum[3]},
+				},
+				Restricted:   []int{1, 2},
+				Stem:         40,
+				Eliminations: []Candidate{{0, 3}},
+			},
+			desc: "Death Blossom: stem r5c5, 1: r1c5 {1,3}, 2: r5c1 {2,3} => r1c1<>3",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tc.find(tc.grid, DefaultMaxALSSize)
+			require.True(t, ok)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.desc, got.String())
+		})
+	}
+}
+
+func TestAlmostLockedSets(t *testing.T) {
+	g := candidateGrid(map[int][]int{0: {1, 2}, 1: {2, 3}, 2: {1, 3}})
+	var found [][]int
+	for _, a := range g.almostLockedSets(DefaultMaxALSSize) {
+		if a.Cells[0] < 3 && a.Cells[len(a.Cells)-1] < 3 {
+			found = append(found, a.Cells)
+		}
+	}
+	// each pair is an ALS, but all three squares together are locked
+	assert.Equal(t, [][]int{{0}, {1}, {2}, {0, 1}, {0, 2}, {1, 2}}, found)
+
+	for _, a := range g.almostLockedSets(1) {
+		assert.Len(t, a.Cells, 1)
+	}
+}
+
+func TestFindALSMaxSize(t *testing.T) {
+	// B is r1c5,r2c5, so sets of one square are not enough
+	g := candidateGrid(map[int][]int{0: {1, 2}, 4: {1, 3}, 13: {2, 3}})
+	_, ok := g.findALSXZ(1)
+	assert.False(t, ok)
+	_, ok = g.findALSXZ(2)
+	assert.True(t, ok)
+
+	a := assert.New(t)
+	a.Equal(DefaultMaxALSSize, Limits{}.maxALSSize())
+	a.Equal(3, Limits{MaxALSSize: 3}.maxALSSize())
+}
+
+func TestFindALSXZTooManyLinks(t *testing.T) {
+	// any two of the four squares are an ALS, linked to the other two by
+	// all three values, which cannot fill four squares
+	g := candidateGrid(map[int][]int{0: {1, 2, 3}, 1: {1, 2, 3}, 4: {1, 2, 3}, 5: {1, 2, 3}})
+	_, ok := g.findALSXZ(DefaultMaxALSSize)
+	assert.False(t, ok)
+}
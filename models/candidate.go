@@ -0,0 +1,51 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A Candidate is a single digit that a single square might hold.
+type Candidate struct {
+	Cell  int `json:"cell"`
+	Digit int `json:"digit"`
+}
+
+// String implements the fmt.Stringer interface, using the notation
+// "(5)r1c2" for digit 5 in the first row and second column.
+func (c Candidate) String() string {
+	return "(" + strconv.Itoa(c.Digit) + ")" + CellName(c.Cell)
+}
+
+// formatDigits returns the values of a square as a list, e.g. "{1,5}"
+func formatDigits(sq Square) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, d := range sq.Values() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('0' + byte(d))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// formatCells returns a list of square names, e.g. "r1c1,r1c2"
+func formatCells(cells []int) string {
+	names := make([]string, len(cells))
+	for i, c := range cells {
+		names[i] = CellName(c)
+	}
+	return strings.Join(names, ",")
+}
+
+// formatEliminations returns a list of removed candidates,
+// e.g. "r1c1<>5, r2c2<>5"
+func formatEliminations(cs []Candidate) string {
+	elims := make([]string, len(cs))
+	for i, c := range cs {
+		elims[i] = CellName(c.Cell) + "<>" + strconv.Itoa(c.Digit)
+	}
+	return strings.Join(elims, ", ")
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandidateString(t *testing.T) {
+	assert.Equal(t, "(5)r1c2", Candidate{1, 5}.String())
+	assert.Equal(t, "r1c2<>5, r9c9<>1",
+		formatEliminations([]Candidate{{1, 5}, {80, 1}}))
+	assert.Equal(t, "{1,5,9}", formatDigits(one|five|nine))
+}
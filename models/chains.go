@@ -0,0 +1,395 @@
+package models
+
+import "strings"
+
+// A ChainKind identifies one of the chain techniques.
+type ChainKind int
+
+const (
+	// XChain is an alternating inference chain on a single digit, whose
+	// strong links are all conjugate pairs.
+	XChain ChainKind = iota
+	// XYChain is an alternating inference chain through bivalue squares,
+	// whose strong links are all within a square.
+	XYChain
+	// AIC is an alternating inference chain that may mix both kinds of
+	// strong and weak link. One of its two ends must be true.
+	AIC
+	// ContinuousNiceLoop is a chain whose ends are weakly linked, closing
+	// a loop in which every weak link must also be strong.
+	ContinuousNiceLoop
+	// DiscontinuousNiceLoop is a chain that begins and ends in the same
+	// square, so that the square must hold one of the two ends.
+	DiscontinuousNiceLoop
+)
+
+// String implements the fmt.Stringer interface
+func (k ChainKind) String() string {
+	switch k {
+	case XChain:
+		return "X-Chain"
+	case XYChain:
+		return "XY-Chain"
+	case AIC:
+		return "AIC"
+	case ContinuousNiceLoop:
+		return "Continuous Nice Loop"
+	case DiscontinuousNiceLoop:
+		return "Discontinuous Nice Loop"
+	default:
+		return "Chain"
+	}
+}
+
+// A Chain describes an alternating inference chain. Nodes holds the
+// candidates of the chain in order, and the links between them alternate
+// strong and weak, starting and ending with a strong link:
+// Nodes[0]=Nodes[1]-Nodes[2]=...=Nodes[n-1].
+//
+// A strong link means that at least one of the two candidates is true;
+// a weak link means that at most one of them is. So at least one end of
+// the chain is true, and any candidate that is weakly linked to both ends
+// can be eliminated. For a continuous nice loop, the last node is weakly
+// linked back to the first.
+type Chain struct {
+	Kind         ChainKind
+	Nodes        []Candidate
+	Eliminations []Candidate
+}
+
+// Name returns the conventional name of the chain, e.g. "X-Chain"
+func (c Chain) Name() string {
+	return c.Kind.String()
+}
+
+// String implements the fmt.Stringer interface
+func (c Chain) String() string {
+	return c.Name() + ": " + c.Eureka() + " => " + formatEliminations(c.Eliminations)
+}
+
+// Eureka returns the chain in Eureka notation, e.g.
+// "(5)r1c2=(5)r1c7-(5)r3c8=(5)r3c1". A strong link within a square is
+// written as "(1=2)r4c5". A continuous nice loop repeats its first node
+// at the end.
+func (c Chain) Eureka() string {
+	var b strings.Builder
+	for i := 0; i < len(c.Nodes); {
+		n := c.Nodes[i]
+		if i > 0 {
+			b.WriteString(linkSymbol(i - 1))
+		}
+		if i%2 == 0 && i+1 < len(c.Nodes) && c.Nodes[i+1].Cell == n.Cell {
+			b.WriteByte('(')
+			b.WriteByte('0' + byte(n.Digit))
+			b.WriteByte('=')
+			b.WriteByte('0' + byte(c.Nodes[i+1].Digit))
+			b.WriteByte(')')
+			b.WriteString(CellName(n.Cell))
+			i += 2
+			continue
+		}
+		b.WriteString(n.String())
+		i++
+	}
+	if c.Kind == ContinuousNiceLoop && len(c.Nodes) > 0 {
+		b.WriteString(linkSymbol(len(c.Nodes) - 1))
+		b.WriteString(c.Nodes[0].String())
+	}
+	return b.String()
+}
+
+// linkSymbol returns the symbol for the i'th link of a chain, which is
+// strong for even i and weak for odd i.
+func linkSymbol(i int) string {
+	if i%2 == 0 {
+		return "="
+	}
+	return "-"
+}
+
+// findXChain looks for an X-Chain of at most maxLen candidates that allows
+// at least one candidate to be eliminated.
+func (g Grid) findXChain(maxLen int) (Chain, bool) {
+	return g.findChain(XChain, maxLen)
+}
+
+// findXYChain looks for an XY-Chain of at most maxLen candidates that
+// allows at least one candidate to be eliminated.
+func (g Grid) findXYChain(maxLen int) (Chain, bool) {
+	return g.findChain(XYChain, maxLen)
+}
+
+// findAIC looks for an alternating inference chain or nice loop of at most
+// maxLen candidates that allows at least one candidate to be eliminated.
+func (g Grid) findAIC(maxLen int) (Chain, bool) {
+	return g.findChain(AIC, maxLen)
+}
+
+// A chainGraph holds the strong and weak links between the candidates of
+// a grid. Each candidate is a node, numbered cell*9 + digit-1.
+type chainGraph struct {
+	strong, weak [729][]int
+}
+
+func nodeOf(c Candidate) int {
+	return c.Cell*9 + c.Digit - 1
+}
+
+func candidateOf(n int) Candidate {
+	return Candidate{n / 9, n%9 + 1}
+}
+
+// chainGraph builds the links that a chain of the given kind may use.
+// An X-Chain only uses links between squares on the same digit, and an
+// XY-Chain only uses bivalue squares, linked strongly within each square
+// and weakly between them.
+func (g Grid) chainGraph(kind ChainKind) *chainGraph {
+	cg := new(chainGraph)
+	for cell := 0; cell < 81; cell++ {
+		sq := g.squares[cell]
+		if sq.IsDefined() || sq == none {
+			continue
+		}
+		if kind == XYChain && sq.Count() != 2 {
+			continue
+		}
+		for _, d := range sq.Values() {
+			n := nodeOf(Candidate{cell, d})
+			if kind != XYChain {
+				for _, u := range cellUnits[cell] {
+					if pos := g.positions(u, d); len(pos) == 2 {
+						other := pos[0]
+						if other == cell {
+							other = pos[1]
+						}
+						addLink(&cg.strong[n], nodeOf(Candidate{other, d}))
+					}
+				}
+			}
+			if kind != XChain {
+				for _, e := range sq.Values() {
+					if e == d {
+						continue
+					}
+					if sq.Count() == 2 {
+						addLink(&cg.strong[n], nodeOf(Candidate{cell, e}))
+					}
+					if kind == AIC {
+						addLink(&cg.weak[n], nodeOf(Candidate{cell, e}))
+					}
+				}
+			}
+			for _, p := range peers[cell] {
+				other := g.squares[p]
+				if other.IsDefined() || !other.Has(d) {
+					continue
+				}
+				if kind == XYChain && other.Count() != 2 {
+					continue
+				}
+				addLink(&cg.weak[n], nodeOf(Candidate{p, d}))
+			}
+		}
+	}
+	return cg
+}
+
+func addLink(links *[]int, n int) {
+	if !containsInt(*links, n) {
+		*links = append(*links, n)
+	}
+}
+
+// findChain looks for the shortest chain of the given kind, with at most
+// maxLen candidates, that allows at least one candidate to be eliminated.
+//
+// From each starting candidate, it searches breadth first for the
+// candidates that must be true if the start is false, alternately
+// following strong links (from a false candidate to a true one) and weak
+// links (from a true candidate to a false one). Each true candidate that
+// is reached is the end of a chain.
+func (g Grid) findChain(kind ChainKind, maxLen int) (Chain, bool) {
+	cg := g.chainGraph(kind)
+	var best Chain
+	for s := 0; s < 729; s++ {
+		if len(cg.strong[s]) == 0 {
+			continue
+		}
+		limit := maxLen
+		if best.Nodes != nil {
+			limit = len(best.Nodes) - 1
+		}
+		if c, ok := g.chainFrom(cg, kind, s, limit); ok {
+			best = c
+			if len(best.Nodes) == 4 {
+				break
+			}
+		}
+	}
+	return best, best.Nodes != nil
+}
+
+// chainFrom searches for the shortest chain starting from node s, with at
+// most maxLen candidates, that allows at least one candidate to be
+// eliminated.
+func (g Grid) chainFrom(cg *chainGraph, kind ChainKind, s, maxLen int) (Chain, bool) {
+	// each state is a node that is false (state node*2) or true (node*2+1)
+	var (
+		parent [729 * 2]int
+		depth  [729 * 2]int
+	)
+	for i := range parent {
+		parent[i] = -1
+	}
+	start := s * 2
+	depth[start] = 1
+	queue := []int{start}
+
+	// onPath reports whether node n is already in the chain ending at state
+	onPath := func(state, n int) bool {
+		for ; state >= 0; state = parent[state] {
+			if state/2 == n {
+				return true
+			}
+		}
+		return false
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+		if depth[state] >= maxLen {
+			continue
+		}
+		n, on := state/2, state%2 == 1
+		links := cg.strong[n]
+		if on {
+			links = cg.weak[n]
+		}
+		for _, m := range links {
+			next := m * 2
+			if !on {
+				next++
+			}
+			if next == start || depth[next] != 0 {
+				continue
+			}
+			if onPath(state, m) && !(m == s && !on) {
+				continue
+			}
+			parent[next] = state
+			depth[next] = depth[state] + 1
+			if !on && depth[next] >= 4 {
+				if c, ok := g.chainTo(parent[:], next); ok {
+					c.Kind = chainKind(kind, c)
+					return c, true
+				}
+			}
+			queue = append(queue, next)
+		}
+	}
+	return Chain{}, false
+}
+
+// chainTo builds the chain that ends with the true state end, and finds
+// its eliminations. Returns false if there is nothing to eliminate.
+func (g Grid) chainTo(parent []int, end int) (Chain, bool) {
+	var nodes []Candidate
+	for state := end; state >= 0; state = parent[state] {
+		nodes = append(nodes, candidateOf(state/2))
+	}
+	for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+		nodes[i], nodes[j] = nodes[j], nodes[i]
+	}
+	first, last := nodes[0], nodes[len(nodes)-1]
+
+	var elims []Candidate
+	switch {
+	case first == last:
+		// the first candidate is true even if it is false
+		elims = g.candidatesWhere(func(x Candidate) bool {
+			return weaklyLinked(x, first)
+		})
+		if len(elims) == 0 {
+			return Chain{}, false
+		}
+		return Chain{Kind: DiscontinuousNiceLoop, Nodes: nodes, Eliminations: elims}, true
+
+	case weaklyLinked(first, last):
+		// every weak link in the loop, including last-first, is also strong
+		elims = g.candidatesWhere(func(x Candidate) bool {
+			if containsCandidate(nodes, x) {
+				return false
+			}
+			for i := 1; i < len(nodes); i += 2 {
+				next := nodes[(i+1)%len(nodes)]
+				if weaklyLinked(x, nodes[i]) && weaklyLinked(x, next) {
+					return true
+				}
+			}
+			return false
+		})
+		if len(elims) == 0 {
+			return Chain{}, false
+		}
+		return Chain{Kind: ContinuousNiceLoop, Nodes: nodes, Eliminations: elims}, true
+
+	default:
+		elims = g.candidatesWhere(func(x Candidate) bool {
+			return weaklyLinked(x, first) && weaklyLinked(x, last)
+		})
+		if len(elims) == 0 {
+			return Chain{}, false
+		}
+		return Chain{Kind: AIC, Nodes: nodes, Eliminations: elims}, true
+	}
+}
+
+// chainKind names a chain found while searching for the given kind.
+func chainKind(kind ChainKind, c Chain) ChainKind {
+	switch {
+	case c.Kind == ContinuousNiceLoop:
+		return ContinuousNiceLoop
+	case c.Kind == DiscontinuousNiceLoop || c.Nodes[0].Cell == c.Nodes[len(c.Nodes)-1].Cell:
+		return DiscontinuousNiceLoop
+	default:
+		return kind
+	}
+}
+
+// weaklyLinked reports whether candidates a and b cannot both be true,
+// because they are different digits in the same square, or the same digit
+// in squares that see each other.
+func weaklyLinked(a, b Candidate) bool {
+	if a.Cell == b.Cell {
+		return a.Digit != b.Digit
+	}
+	return a.Digit == b.Digit && Sees(a.Cell, b.Cell)
+}
+
+// candidatesWhere returns every candidate in an undefined square that
+// matches the given filter.
+func (g Grid) candidatesWhere(match func(Candidate) bool) []Candidate {
+	var out []Candidate
+	for n := 0; n < 81; n++ {
+		sq := g.squares[n]
+		if sq.IsDefined() {
+			continue
+		}
+		for _, d := range sq.Values() {
+			if c := (Candidate{n, d}); match(c) {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+func containsCandidate(cs []Candidate, c Candidate) bool {
+	for _, x := range cs {
+		if x == c {
+			return true
+		}
+	}
+	return false
+}
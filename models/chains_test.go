@@ -0,0 +1,113 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindChain(t *testing.T) {
+	// conjugate pairs on 5 in rows 1, 5 and 9
+	xchain := onlyIn(map[int][]int{}, 5, []int{0}, []int{0, 4})
+	xchain = onlyIn(xchain, 5, []int{4}, []int{4, 8})
+	xchain = onlyIn(xchain, 5, []int{8}, []int{2, 8})
+
+	tt := []struct {
+		name string
+		grid Grid
+		find func(Grid, int) (Chain, bool)
+		want Chain
+		desc string
+	}{
+		{
+			name: "x-chain",
+			grid: candidateGrid(xchain),
+			find: Grid.findXChain,
+			want: Chain{
+				Kind: XChain,
+				Nodes: []Candidate{
+					{0, 5}, {4, 5}, {40, 5}, {44, 5}, {80, 5}, {74, 5},
+				},
+				Eliminations: []Candidate{{11, 5}, {20, 5}, {54, 5}, {63, 5}},
+			},
+			desc: "X-Chain: (5)r1c1=(5)r1c5-(5)r5c5=(5)r5c9-(5)r9c9=(5)r9c3 " +
+				"=> r2c3<>5, r3c3<>5, r7c1<>5, r8c1<>5",
+		},
+		{
+			name: "xy-chain",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 4: {2, 3}, 40: {3, 4}, 37: {4, 1}}),
+			find: Grid.findXYChain,
+			want: Chain{
+				Kind: XYChain,
+				Nodes: []Candidate{
+					{0, 1}, {0, 2}, {4, 2}, {4, 3}, {40, 3}, {40, 4}, {37, 4}, {37, 1},
+				},
+				Eliminations: []Candidate{
+					{1, 1}, {10, 1}, {19, 1}, {27, 1}, {36, 1}, {45, 1},
+				},
+			},
+			desc: "XY-Chain: (1=2)r1c1-(2=3)r1c5-(3=4)r5c5-(4=1)r5c2 " +
+				"=> r1c2<>1, r2c2<>1, r3c2<>1, r4c1<>1, r5c1<>1, r6c1<>1",
+		},
+		{
+			name: "continuous nice loop",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 4: {2, 3}, 40: {3, 4}, 36: {4, 1}}),
+			find: Grid.findAIC,
+			want: Chain{
+				Kind: ContinuousNiceLoop,
+				Nodes: []Candidate{
+					{0, 1}, {0, 2}, {4, 2}, {4, 3}, {40, 3}, {40, 4}, {36, 4}, {36, 1},
+				},
+				Eliminations: []Candidate{
+					{1, 2}, {2, 2}, {3, 2}, {5, 2}, {6, 2}, {7, 2}, {8, 2},
+					{9, 1}, {13, 3}, {18, 1}, {22, 3}, {27, 1}, {31, 3},
+					{37, 4}, {38, 4}, {39, 4}, {41, 4}, {42, 4}, {43, 4}, {44, 4},
+					{45, 1}, {49, 3}, {54, 1}, {58, 3}, {63, 1}, {67, 3}, {72, 1}, {76, 3},
+				},
+			},
+			desc: "Continuous Nice Loop: (1=2)r1c1-(2=3)r1c5-(3=4)r5c5-(4=1)r5c1-(1)r1c1 => " +
+				"r1c2<>2, r1c3<>2, r1c4<>2, r1c6<>2, r1c7<>2, r1c8<>2, r1c9<>2, " +
+				"r2c1<>1, r2c5<>3, r3c1<>1, r3c5<>3, r4c1<>1, r4c5<>3, " +
+				"r5c2<>4, r5c3<>4, r5c4<>4, r5c6<>4, r5c7<>4, r5c8<>4, r5c9<>4, " +
+				"r6c1<>1, r6c5<>3, r7c1<>1, r7c5<>3, r8c1<>1, r8c5<>3, r9c1<>1, r9c5<>3",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tc.find(tc.grid, DefaultMaxChainLength)
+			require.True(t, ok)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.desc, got.String())
+		})
+	}
+}
+
+func TestFindChainMaxLength(t *testing.T) {
+	g := candidateGrid(map[int][]int{0: {1, 2}, 4: {2, 3}, 40: {3, 4}, 37: {4, 1}})
+
+	_, ok := g.findXYChain(6)
+	assert.False(t, ok)
+
+	_, ok = g.findXYChain(8)
+	assert.True(t, ok)
+
+	// each technique keeps its own limit
+	short := BuiltinTechniquesWith(PassXYChain, Limits{MaxChainLength: 6})[0]
+	long := BuiltinTechniques(PassXYChain)[0]
+	_, ok = short.Apply(&g)
+	assert.False(t, ok)
+	_, ok = long.Apply(&g)
+	assert.True(t, ok)
+}
+
+func TestChainEureka(t *testing.T) {
+	c := Chain{
+		Kind:  DiscontinuousNiceLoop,
+		Nodes: []Candidate{{0, 1}, {4, 1}, {4, 3}, {40, 3}, {36, 3}, {0, 3}},
+	}
+	assert.Equal(t, "(1)r1c1=(1)r1c5-(3)r1c5=(3)r5c5-(3)r5c1=(3)r1c1", c.Eureka())
+}
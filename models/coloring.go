@@ -0,0 +1,257 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A ColoringKind identifies one of the coloring techniques.
+type ColoringKind int
+
+const (
+	// ColorTrap removes the digit from squares that see both colors of
+	// a cluster, since one of the two colors must be true.
+	ColorTrap ColoringKind = iota
+	// ColorWrap removes the digit from every square of a color when two
+	// squares of that color see each other, since the color must be false.
+	ColorWrap
+	// MultiColoring combines two clusters that are joined by squares
+	// which see each other.
+	MultiColoring
+)
+
+// String implements the fmt.Stringer interface
+func (k ColoringKind) String() string {
+	switch k {
+	case ColorTrap:
+		return "Color Trap"
+	case ColorWrap:
+		return "Color Wrap"
+	case MultiColoring:
+		return "Multi-Coloring"
+	default:
+		return "Coloring"
+	}
+}
+
+// A Coloring describes an elimination made by coloring the conjugate pairs
+// of a single digit. Squares joined by a chain of conjugate pairs (units in
+// which the digit can only go in two squares) form a cluster, and each
+// cluster is split into two colors, exactly one of which holds the digit.
+type Coloring struct {
+	Kind  ColoringKind
+	Digit int
+	// Colors holds the squares of each color. Colors[0] and Colors[1] are
+	// the two halves of the first cluster; for MultiColoring, Colors[2] and
+	// Colors[3] are the two halves of the second cluster.
+	Colors       [][]int
+	Eliminations []Candidate
+}
+
+// Name returns the conventional name of the technique, e.g. "Color Trap"
+func (c Coloring) Name() string {
+	return c.Kind.String()
+}
+
+// String implements the fmt.Stringer interface
+func (c Coloring) String() string {
+	var b strings.Builder
+	b.WriteString(c.Name())
+	b.WriteString(": ")
+	b.WriteString(strconv.Itoa(c.Digit))
+	for i, cells := range c.Colors {
+		b.WriteString(" ")
+		b.WriteString(colorNames[i])
+		b.WriteString(" ")
+		b.WriteString(formatCells(cells))
+	}
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(c.Eliminations))
+	return b.String()
+}
+
+var colorNames = [...]string{"A", "a", "B", "b"}
+
+// findSimpleColoring looks for a color wrap or a color trap that allows
+// at least one candidate to be eliminated.
+func (g Grid) findSimpleColoring() (Coloring, bool) {
+	for d := 1; d <= 9; d++ {
+		for _, cl := range g.clusters(d) {
+			for _, color := range cl {
+				if !seeEachOther(color) {
+					continue
+				}
+				var elims []Candidate
+				for _, c := range color {
+					elims = append(elims, Candidate{c, d})
+				}
+				return Coloring{
+					Kind:         ColorWrap,
+					Digit:        d,
+					Colors:       [][]int{cl[0], cl[1]},
+					Eliminations: elims,
+				}, true
+			}
+
+			elims := g.eliminationsWhere(d, func(n int) bool {
+				return seesAny(n, cl[0]) && seesAny(n, cl[1])
+			})
+			if len(elims) > 0 {
+				return Coloring{
+					Kind:         ColorTrap,
+					Digit:        d,
+					Colors:       [][]int{cl[0], cl[1]},
+					Eliminations: elims,
+				}, true
+			}
+		}
+	}
+	return Coloring{}, false
+}
+
+// findMultiColoring looks for two clusters of the same digit that together
+// allow at least one candidate to be eliminated.
+func (g Grid) findMultiColoring() (Coloring, bool) {
+	for d := 1; d <= 9; d++ {
+		clusters := g.clusters(d)
+		for i, x := range clusters {
+			for j, y := range clusters {
+				if i == j {
+					continue
+				}
+				if elims, ok := g.multiColoring(d, x, y); ok {
+					return Coloring{
+						Kind:         MultiColoring,
+						Digit:        d,
+						Colors:       [][]int{x[0], x[1], y[0], y[1]},
+						Eliminations: elims,
+					}, true
+				}
+			}
+		}
+	}
+	return Coloring{}, false
+}
+
+// multiColoring finds the eliminations for digit d that follow from the
+// clusters x and y.
+//
+// If a color of x sees both colors of y, then it must be false.
+// Otherwise, if a color of x sees a color of y, then they cannot both be
+// true, so at least one of their opposite colors is true, and any square
+// that sees both of those can be eliminated.
+func (g Grid) multiColoring(d int, x, y [2][]int) ([]Candidate, bool) {
+	for i := 0; i < 2; i++ {
+		if colorsSee(x[i], y[0]) && colorsSee(x[i], y[1]) {
+			var elims []Candidate
+			for _, c := range x[i] {
+				elims = append(elims, Candidate{c, d})
+			}
+			return elims, true
+		}
+	}
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			if !colorsSee(x[i], y[j]) {
+				continue
+			}
+			xo, yo := x[1-i], y[1-j]
+			elims := g.eliminationsWhere(d, func(n int) bool {
+				return !containsInt(xo, n) && !containsInt(yo, n) &&
+					seesAny(n, xo) && seesAny(n, yo)
+			})
+			if len(elims) > 0 {
+				return elims, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// clusters splits the conjugate pairs of digit d into connected clusters,
+// each of which is split into two colors. Clusters that cannot be colored
+// consistently are ignored.
+func (g Grid) clusters(d int) [][2][]int {
+	var adj [81][]int
+	for _, l := range g.strongLinks(d) {
+		if !containsInt(adj[l.a], l.b) {
+			adj[l.a] = append(adj[l.a], l.b)
+			adj[l.b] = append(adj[l.b], l.a)
+		}
+	}
+
+	var (
+		clusters [][2][]int
+		color    [81]int // 0 means uncolored, otherwise 1 or 2
+	)
+	for start := 0; start < 81; start++ {
+		if color[start] != 0 || len(adj[start]) == 0 {
+			continue
+		}
+		var cl [2][]int
+		ok := true
+		color[start] = 1
+		queue := []int{start}
+		for len(queue) > 0 {
+			n := queue[0]
+			queue = queue[1:]
+			cl[color[n]-1] = append(cl[color[n]-1], n)
+			for _, m := range adj[n] {
+				switch color[m] {
+				case 0:
+					color[m] = 3 - color[n]
+					queue = append(queue, m)
+				case color[n]:
+					ok = false
+				}
+			}
+		}
+		if ok {
+			clusters = append(clusters, cl)
+		}
+	}
+	return clusters
+}
+
+// eliminationsWhere returns the candidates for digit d in every undefined
+// square that matches the given filter.
+func (g Grid) eliminationsWhere(d int, match func(n int) bool) []Candidate {
+	var elims []Candidate
+	for n := 0; n < 81; n++ {
+		sq := g.squares[n]
+		if sq.Has(d) && !sq.IsDefined() && match(n) {
+			elims = append(elims, Candidate{n, d})
+		}
+	}
+	return elims
+}
+
+// seesAny reports whether square n sees at least one of the given cells.
+func seesAny(n int, cells []int) bool {
+	for _, c := range cells {
+		if Sees(n, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// seeEachOther reports whether any two of the given cells see each other.
+func seeEachOther(cells []int) bool {
+	for i, a := range cells {
+		if seesAny(a, cells[i+1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// colorsSee reports whether any square in a sees any square in b.
+func colorsSee(a, b []int) bool {
+	for _, n := range a {
+		if seesAny(n, b) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindColoring(t *testing.T) {
+	// conjugate pairs on 7 at r1c1=r1c6, r1c6=r6c6 and r6c6=r6c3
+	trap := onlyIn(map[int][]int{}, 7, []int{0}, []int{0, 5})
+	trap = transpose(onlyIn(transpose(trap), 7, []int{5}, []int{0, 5}))
+	trap = onlyIn(trap, 7, []int{5}, []int{2, 5})
+
+	// as above, but ending at r6c2, which is conjugate to r5c1 in block 4
+	wrap := onlyIn(map[int][]int{}, 7, []int{0}, []int{0, 5})
+	wrap = transpose(onlyIn(transpose(wrap), 7, []int{5}, []int{0, 5}))
+	wrap = onlyIn(wrap, 7, []int{5}, []int{1, 5})
+	wrap = excluding(wrap, 7, 27, 28, 29, 37, 38)
+
+	// two separate clusters on 8, at r1c2=r1c8 and r3c1=r3c7
+	multi := onlyIn(map[int][]int{}, 8, []int{0}, []int{1, 7})
+	multi = onlyIn(multi, 8, []int{2}, []int{0, 6})
+
+	tt := []struct {
+		name string
+		grid Grid
+		find func(Grid) (Coloring, bool)
+		want Coloring
+		desc string
+	}{
+		{
+			name: "color trap",
+			grid: candidateGrid(trap),
+			find: Grid.findSimpleColoring,
+			want: Coloring{
+				Kind:         ColorTrap,
+				Digit:        7,
+				Colors:       [][]int{{0, 50}, {5, 47}},
+				Eliminations: []Candidate{{11, 7}, {20, 7}, {27, 7}, {36, 7}},
+			},
+			desc: "Color Trap: 7 A r1c1,r6c6 a r1c6,r6c3 => r2c3<>7, r3c3<>7, r4c1<>7, r5c1<>7",
+		},
+		{
+			name: "color wrap",
+			grid: candidateGrid(wrap),
+			find: Grid.findSimpleColoring,
+			want: Coloring{
+				Kind:         ColorWrap,
+				Digit:        7,
+				Colors:       [][]int{{0, 50, 36}, {5, 46}},
+				Eliminations: []Candidate{{0, 7}, {50, 7}, {36, 7}},
+			},
+			desc: "Color Wrap: 7 A r1c1,r6c6,r5c1 a r1c6,r6c2 => r1c1<>7, r6c6<>7, r5c1<>7",
+		},
+		{
+			name: "multi-coloring",
+			grid: candidateGrid(multi),
+			find: Grid.findMultiColoring,
+			want: Coloring{
+				Kind:         MultiColoring,
+				Digit:        8,
+				Colors:       [][]int{{1}, {7}, {18}, {24}},
+				Eliminations: []Candidate{{15, 8}, {16, 8}, {17, 8}},
+			},
+			desc: "Multi-Coloring: 8 A r1c2 a r1c8 B r3c1 b r3c7 => r2c7<>8, r2c8<>8, r2c9<>8",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tc.find(tc.grid)
+			require.True(t, ok)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.desc, got.String())
+		})
+	}
+}
+
+func TestColoringStep(t *testing.T) {
+	// two separate clusters on 8, at r1c2=r1c8 and r3c1=r3c7
+	multi := onlyIn(map[int][]int{}, 8, []int{0}, []int{1, 7})
+	multi = onlyIn(multi, 8, []int{2}, []int{0, 6})
+	g := candidateGrid(multi)
+	step, ok := BuiltinTechniques(PassMultiColoring)[0].Apply(&g)
+	require.True(t, ok)
+
+	a := assert.New(t)
+	a.Equal(8, step.Digit)
+	if a.Len(step.Colors, 4, "two colors for each of two clusters") {
+		a.Equal([]int{1}, step.Colors[0])
+		a.Equal([]int{7}, step.Colors[1])
+		a.Equal([]int{18}, step.Colors[2])
+		a.Equal([]int{24}, step.Colors[3])
+	}
+}
+
+func TestFindSimpleColoringNone(t *testing.T) {
+	multi := onlyIn(map[int][]int{}, 8, []int{0}, []int{1, 7})
+	multi = onlyIn(multi, 8, []int{2}, []int{0, 6})
+	_, ok := candidateGrid(multi).findSimpleColoring()
+	assert.False(t, ok)
+}
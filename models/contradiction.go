@@ -0,0 +1,121 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+)
+
+// A ContradictionKind says what makes a grid impossible to solve.
+type ContradictionKind int
+
+const (
+	// NoCandidates means that a square has no possible value.
+	NoCandidates ContradictionKind = iota
+	// Duplicate means that a unit has two squares defined as the same digit.
+	Duplicate
+	// Missing means that a unit has nowhere left for some digits, or only
+	// one square left for more than one digit.
+	Missing
+)
+
+// String implements the fmt.Stringer interface
+func (k ContradictionKind) String() string {
+	switch k {
+	case NoCandidates:
+		return "no candidates"
+	case Duplicate:
+		return "duplicate"
+	case Missing:
+		return "missing"
+	default:
+		return "contradiction " + strconv.Itoa(int(k))
+	}
+}
+
+// A ContradictionError describes why a grid cannot be solved.
+// Use errors.As to inspect it.
+type ContradictionError struct {
+	Kind ContradictionKind
+	// Cell is the square where the contradiction was found: the square
+	// with no candidates, the second square defined as a duplicate digit,
+	// or the only square left for the missing digits. It is -1 if no
+	// square of the unit is left for the missing digits.
+	Cell int
+	// Unit is the row, column or block where the contradiction was found,
+	// or nil for a square with no candidates.
+	Unit *Unit
+	// Digits are the digits involved: the duplicated or missing digits,
+	// or the last candidates removed from a square, if known.
+	Digits []int
+	// Pass and Technique identify the step after which the contradiction
+	// was detected. Pass is zero if the pass is not known, and Technique
+	// is empty if the grid was already invalid.
+	Pass      Pass
+	Technique string
+}
+
+// Error implements the error interface, e.g. "row 1 has more than one 5"
+func (e *ContradictionError) Error() string {
+	var msg string
+	switch {
+	case e.Kind == NoCandidates:
+		msg = "no possible value for " + CellName(e.Cell)
+	case e.Unit == nil:
+		msg = e.Kind.String() + " " + formatDigitList(e.Digits)
+	case e.Kind == Duplicate:
+		msg = e.Unit.String() + " has more than one " + formatDigitList(e.Digits)
+	case e.Cell >= 0:
+		msg = e.Unit.String() + " has only " + CellName(e.Cell) +
+			" for " + formatDigitList(e.Digits)
+	default:
+		msg = e.Unit.String() + " has nowhere for " + formatDigitList(e.Digits)
+	}
+	if e.Technique != "" {
+		msg += " (after " + e.Technique + ")"
+	}
+	return msg
+}
+
+// contradiction returns a ContradictionError for the given square, unit
+// and digits. The unit id is -1 if there is no unit, and digits is none
+// if they are not known.
+func contradiction(kind ContradictionKind, cell, unit int, digits Square) *ContradictionError {
+	e := &ContradictionError{Kind: kind, Cell: cell}
+	if digits != none {
+		e.Digits = digits.Values()
+	}
+	if unit >= 0 {
+		u := unitByID(unit)
+		e.Unit = &u
+	}
+	return e
+}
+
+// DetectedBy records the technique whose step left the grid invalid, and
+// its pass if it is built in, when err is a *ContradictionError.
+// It returns err.
+func DetectedBy(err error, t Technique, s Step) error {
+	var e *ContradictionError
+	if !errors.As(err, &e) {
+		return err
+	}
+	e.Technique = s.Technique
+	if e.Technique == "" {
+		e.Technique = t.Name()
+	}
+	e.Pass = PassOf(t)
+	return err
+}
+
+// formatDigitList returns a single digit as is, e.g. "5", and more than
+// one as a list, e.g. "{3,5}".
+func formatDigitList(digits []int) string {
+	if len(digits) == 1 {
+		return strconv.Itoa(digits[0])
+	}
+	var sq Square
+	for _, d := range digits {
+		sq |= squareEnum[d]
+	}
+	return formatDigits(sq)
+}
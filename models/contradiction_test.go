@@ -0,0 +1,113 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckContradiction(t *testing.T) {
+	row1, col2 := Unit{Row, 0}, Unit{Column, 1}
+	testCases := []struct {
+		name string
+		grid Grid
+		want ContradictionError
+		msg  string
+	}{
+		{
+			name: "no candidates",
+			grid: candidateGrid(map[int][]int{40: {}}),
+			want: ContradictionError{Kind: NoCandidates, Cell: 40},
+			msg:  "no possible value for r5c5",
+		},
+		{
+			name: "duplicate",
+			grid: NewGrid([]byte("5...5")),
+			want: ContradictionError{Kind: Duplicate, Cell: 4, Unit: &row1, Digits: []int{5}},
+			msg:  "row 1 has more than one 5",
+		},
+		{
+			name: "missing",
+			grid: candidateGrid(map[int][]int{1: {1, 2}, 10: {1, 2}, 19: {1, 2},
+				28: {1, 2}, 37: {1, 2}, 46: {1, 2}, 55: {1, 2}, 64: {1, 2}, 73: {1, 2}}),
+			want: ContradictionError{Kind: Missing, Cell: -1, Unit: &col2, Digits: []int{3, 4, 5, 6, 7, 8, 9}},
+			msg:  "column 2 has nowhere for {3,4,5,6,7,8,9}",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			err := tc.grid.Check()
+			var got *ContradictionError
+			require.True(t, errors.As(err, &got))
+			assert.Equal(t, tc.want, *got)
+			assert.Equal(t, tc.msg, err.Error())
+		})
+	}
+}
+
+func TestContradictionError(t *testing.T) {
+	block := Unit{Block, 8}
+	err := &ContradictionError{Kind: Missing, Cell: 80, Unit: &block, Digits: []int{3, 5},
+		Pass: PassDeduce, Technique: "Hidden Single"}
+	assert.Equal(t, "block 9 has only r9c9 for {3,5} (after Hidden Single)", err.Error())
+}
+
+func TestNormalizeContradiction(t *testing.T) {
+	a := assert.New(t)
+
+	// every digit but 9 is in row 1, and 9 is in column 9
+	g := NewGrid([]byte("12345678.........9"))
+	err := g.NormalizeWith(PassReduce)
+	var got *ContradictionError
+	if a.True(errors.As(err, &got)) {
+		a.Equal(Duplicate, got.Kind)
+		a.Equal(&Unit{Column, 8}, got.Unit)
+		a.Equal([]int{9}, got.Digits)
+		a.Equal(PassReduce, got.Pass)
+		a.Equal("Naked Single", got.Technique)
+	}
+
+	// r1c1 is the only square in row 1 left for both 1 and 2
+	g = candidateGrid(nil)
+	for n := 1; n < 9; n++ {
+		g.squares[n] = any &^ (one | two)
+	}
+	err = g.NormalizeWith(PassDeduce)
+	if a.True(errors.As(err, &got)) {
+		a.Equal(ContradictionError{Kind: Missing, Cell: 0, Unit: &Unit{Row, 0},
+			Digits: []int{1, 2}}, *got, "found before any step")
+		a.Equal("row 1 has only r1c1 for {1,2}", err.Error())
+	}
+
+	g = candidateGrid(nil)
+	err = g.Apply(Step{Eliminations: []Candidate{{0, 1}, {0, 2}, {0, 3}, {0, 4}, {0, 5}, {0, 6}, {0, 7}, {0, 8}, {0, 9}}})
+	if a.True(errors.As(err, &got)) {
+		a.Equal(ContradictionError{Kind: NoCandidates, Cell: 0}, *got)
+	}
+}
+
+func TestPropagateContradictionError(t *testing.T) {
+	a := assert.New(t)
+
+	g := NewGrid([]byte("12345678.........9"))
+	err := g.Propagate(BuiltinTechniques(BasicPasses))
+	var got *ContradictionError
+	if a.True(errors.As(err, &got)) {
+		a.Contains([]string{"Hidden Single", "Naked Single"}, got.Technique)
+		a.NotZero(got.Pass & BasicPasses)
+	}
+
+	g = candidateGrid(map[int][]int{0: {1}, 1: {1, 2}})
+	rule := houseRule{name: "Bad", step: Step{Eliminations: []Candidate{{1, 2}}}}
+	err = g.Propagate([]Technique{rule})
+	if a.True(errors.As(err, &got)) {
+		a.Equal(ContradictionError{Kind: Duplicate, Cell: 1, Unit: &Unit{Row, 0},
+			Digits: []int{1}, Technique: "Bad"}, *got)
+		a.Equal("row 1 has more than one 1 (after Bad)", err.Error())
+	}
+}
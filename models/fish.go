@@ -0,0 +1,215 @@
+package models
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// A Fish is a single digit pattern, in which every candidate for the digit
+// in n base units (all rows, or all columns) lies within n cover units
+// running the other way. Since the digit must appear once in each base
+// unit, it fills each cover unit within the base units, and can be removed
+// from the rest of the cover units.
+//
+// A finned fish has extra candidates in its base units (the fins), which
+// must all lie in the same block. Either a fin holds the digit, or the
+// fish is valid, so only the squares in the cover units which also see
+// every fin can be eliminated. A sashimi fish is a finned fish that would
+// be degenerate without its fins, as one of its base units has only one
+// candidate within the cover.
+type Fish struct {
+	Digit        int
+	Base         []Unit
+	Cover        []Unit
+	Fins         []int
+	Sashimi      bool
+	Eliminations []Candidate
+}
+
+var fishSizes = [...]string{2: "X-Wing", 3: "Swordfish", 4: "Jellyfish"}
+
+// Name returns the conventional name of the fish, e.g. "Finned Swordfish"
+func (f Fish) Name() string {
+	name := fishSizes[len(f.Base)]
+	switch {
+	case f.Sashimi:
+		return "Sashimi " + name
+	case len(f.Fins) > 0:
+		return "Finned " + name
+	default:
+		return name
+	}
+}
+
+// String implements the fmt.Stringer interface
+func (f Fish) String() string {
+	var b strings.Builder
+	b.WriteString(f.Name())
+	b.WriteString(": ")
+	b.WriteString(strconv.Itoa(f.Digit))
+	b.WriteString(" in ")
+	b.WriteString(formatUnits(f.Base))
+	b.WriteString(" covered by ")
+	b.WriteString(formatUnits(f.Cover))
+	if len(f.Fins) > 0 {
+		b.WriteString(" with fins at ")
+		b.WriteString(formatCells(f.Fins))
+	}
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(f.Eliminations))
+	return b.String()
+}
+
+// findFish looks for a fish of the given size (2 to 4) that allows at
+// least one candidate to be eliminated. If finned is true, it looks for
+// finned and sashimi fish instead of basic ones.
+func (g Grid) findFish(size int, finned bool) (Fish, bool) {
+	for d := 1; d <= 9; d++ {
+		// base units are rows (0-8), then columns (9-17)
+		for _, first := range [2]int{0, 9} {
+			if f, ok := g.findFishIn(d, first, size, finned); ok {
+				return f, true
+			}
+		}
+	}
+	return Fish{}, false
+}
+
+// findFishIn looks for a fish for digit d with base units from first to
+// first+8, and cover units running the other way.
+func (g Grid) findFishIn(d, first, size int, finned bool) (Fish, bool) {
+	// cross returns the index of the cover unit for a cell
+	cross := func(c int) int { return c % 9 }
+	coverFirst := 9
+	if first == 9 {
+		cross = func(c int) int { return c / 9 }
+		coverFirst = 0
+	}
+
+	// where[i] is the set of cover indices with candidates in base unit i
+	var where [9]uint16
+	lines := make([]int, 0, 9)
+	for i := 0; i < 9; i++ {
+		cells := g.positions(first+i, d)
+		if len(cells) < 2 {
+			continue
+		}
+		for _, c := range cells {
+			where[i] |= 1 << cross(c)
+		}
+		lines = append(lines, i)
+	}
+
+	var found Fish
+	forEachCombination(lines, size, func(base []int) bool {
+		var union uint16
+		for _, i := range base {
+			union |= where[i]
+		}
+		n := bits.OnesCount16(union)
+		if !finned {
+			if n == size {
+				found, _ = g.fish(d, first, coverFirst, base, union, cross)
+			}
+			return found.Base == nil
+		}
+		if n <= size || n > size+3 {
+			return true
+		}
+		forEachCombination(indicesOf(union), size, func(cover []int) bool {
+			var mask uint16
+			for _, i := range cover {
+				mask |= 1 << i
+			}
+			found, _ = g.fish(d, first, coverFirst, base, mask, cross)
+			return found.Base == nil
+		})
+		return found.Base == nil
+	})
+	return found, found.Base != nil
+}
+
+// fish builds the fish for digit d with the given base unit indices and
+// the set of cover unit indices. Base candidates outside the cover become
+// fins. Returns false if the fins are not in a single block, or there is
+// nothing to eliminate.
+func (g Grid) fish(d, first, coverFirst int, base []int, cover uint16, cross func(int) int) (Fish, bool) {
+	var (
+		fins    []int
+		sashimi bool
+	)
+	for _, i := range base {
+		inCover := 0
+		for _, c := range g.positions(first+i, d) {
+			if cover&(1<<cross(c)) == 0 {
+				fins = append(fins, c)
+			} else {
+				inCover++
+			}
+		}
+		if inCover < 2 {
+			sashimi = true
+		}
+	}
+	finBlock := -1
+	for _, c := range fins {
+		if finBlock >= 0 && cellUnits[c][2] != finBlock {
+			return Fish{}, false
+		}
+		finBlock = cellUnits[c][2]
+	}
+
+	var elims []Candidate
+	coverIx := indicesOf(cover)
+	for _, i := range coverIx {
+		for _, c := range unitCells[coverFirst+i] {
+			sq := g.squares[c]
+			if !sq.Has(d) || sq.IsDefined() || containsInt(base, cellUnits[c][first/9]-first) {
+				continue
+			}
+			if finBlock >= 0 && cellUnits[c][2] != finBlock {
+				continue
+			}
+			elims = append(elims, Candidate{c, d})
+		}
+	}
+	if len(elims) == 0 {
+		return Fish{}, false
+	}
+
+	f := Fish{
+		Digit:        d,
+		Fins:         fins,
+		Sashimi:      sashimi && len(fins) > 0,
+		Eliminations: elims,
+	}
+	for _, i := range base {
+		f.Base = append(f.Base, unitByID(first+i))
+	}
+	for _, i := range coverIx {
+		f.Cover = append(f.Cover, unitByID(coverFirst+i))
+	}
+	return f, true
+}
+
+// indicesOf returns the positions of the bits that are set in mask
+func indicesOf(mask uint16) []int {
+	ix := make([]int, 0, bits.OnesCount16(mask))
+	for i := 0; mask != 0; i++ {
+		if mask&1 != 0 {
+			ix = append(ix, i)
+		}
+		mask >>= 1
+	}
+	return ix
+}
+
+// formatUnits returns a list of units, e.g. "row 1, row 5"
+func formatUnits(units []Unit) string {
+	names := make([]string, len(units))
+	for i, u := range units {
+		names[i] = u.String()
+	}
+	return strings.Join(names, ", ")
+}
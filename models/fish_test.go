@@ -0,0 +1,150 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onlyIn restricts digit d within the given rows, so that it can only go
+// in the given columns of each row.
+func onlyIn(restrict map[int][]int, d int, rows, cols []int) map[int][]int {
+	for _, r := range rows {
+		for c := 0; c < 9; c++ {
+			if !containsInt(cols, c) {
+				restrict[r*9+c] = without(d)
+			}
+		}
+	}
+	return restrict
+}
+
+func TestFindFish(t *testing.T) {
+	tt := []struct {
+		name   string
+		grid   Grid
+		size   int
+		finned bool
+		want   Fish
+		title  string
+	}{
+		{
+			name: "x-wing",
+			grid: candidateGrid(onlyIn(map[int][]int{}, 4, []int{0, 4}, []int{1, 6})),
+			size: 2,
+			want: Fish{
+				Digit: 4,
+				Base:  []Unit{{Row, 0}, {Row, 4}},
+				Cover: []Unit{{Column, 1}, {Column, 6}},
+				Eliminations: []Candidate{
+					{10, 4}, {19, 4}, {28, 4}, {46, 4}, {55, 4}, {64, 4}, {73, 4},
+					{15, 4}, {24, 4}, {33, 4}, {51, 4}, {60, 4}, {69, 4}, {78, 4},
+				},
+			},
+			title: "X-Wing",
+		},
+		{
+			name: "swordfish in columns",
+			grid: candidateGrid(
+				transpose(onlyIn(map[int][]int{}, 8, []int{2, 5, 8}, []int{0, 3, 4}))),
+			size: 3,
+			want: Fish{
+				Digit: 8,
+				Base:  []Unit{{Column, 2}, {Column, 5}, {Column, 8}},
+				Cover: []Unit{{Row, 0}, {Row, 3}, {Row, 4}},
+				Eliminations: []Candidate{
+					{0, 8}, {1, 8}, {3, 8}, {4, 8}, {6, 8}, {7, 8},
+					{27, 8}, {28, 8}, {30, 8}, {31, 8}, {33, 8}, {34, 8},
+					{36, 8}, {37, 8}, {39, 8}, {40, 8}, {42, 8}, {43, 8},
+				},
+			},
+			title: "Swordfish",
+		},
+		{
+			name: "finned x-wing",
+			grid: candidateGrid(onlyIn(onlyIn(map[int][]int{},
+				4, []int{0}, []int{1, 6, 7}),
+				4, []int{4}, []int{1, 6})),
+			size:   2,
+			finned: true,
+			want: Fish{
+				Digit:        4,
+				Base:         []Unit{{Row, 0}, {Row, 4}},
+				Cover:        []Unit{{Column, 1}, {Column, 6}},
+				Fins:         []int{7},
+				Eliminations: []Candidate{{15, 4}, {24, 4}},
+			},
+			title: "Finned X-Wing",
+		},
+		{
+			name: "sashimi x-wing",
+			grid: candidateGrid(onlyIn(onlyIn(map[int][]int{},
+				4, []int{0}, []int{1, 6}),
+				4, []int{4}, []int{1, 7})),
+			size:   2,
+			finned: true,
+			want: Fish{
+				Digit:        4,
+				Base:         []Unit{{Row, 0}, {Row, 4}},
+				Cover:        []Unit{{Column, 1}, {Column, 6}},
+				Fins:         []int{43},
+				Sashimi:      true,
+				Eliminations: []Candidate{{33, 4}, {51, 4}},
+			},
+			title: "Sashimi X-Wing",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tc.grid.findFish(tc.size, tc.finned)
+			require.True(t, ok)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.title, got.Name())
+
+			if tc.finned {
+				_, ok := tc.grid.findFish(tc.size, false)
+				assert.False(t, ok, "should not be a basic fish")
+			}
+		})
+	}
+}
+
+func TestFishString(t *testing.T) {
+	g := candidateGrid(onlyIn(onlyIn(map[int][]int{},
+		4, []int{0}, []int{1, 6, 7}),
+		4, []int{4}, []int{1, 6}))
+	f, ok := g.findFish(2, true)
+	require.True(t, ok)
+	assert.Equal(t, "Finned X-Wing: 4 in row 1, row 5 covered by column 2, column 7 "+
+		"with fins at r1c8 => r2c7<>4, r3c7<>4", f.String())
+}
+
+func TestFishStep(t *testing.T) {
+	g := candidateGrid(onlyIn(onlyIn(map[int][]int{},
+		4, []int{0}, []int{1, 6, 7}),
+		4, []int{4}, []int{1, 6}))
+	step, ok := BuiltinTechniques(PassFinnedXWing)[0].Apply(&g)
+	require.True(t, ok)
+
+	a := assert.New(t)
+	a.Equal("Finned X-Wing", step.Technique)
+	a.Equal(4, step.Digit)
+	a.Equal([]Unit{{Row, 0}, {Row, 4}}, step.Base)
+	a.Equal([]Unit{{Column, 1}, {Column, 6}}, step.Cover)
+	a.Equal([]int{7}, step.Fins)
+	a.Equal([]int{1, 6, 37, 42}, step.Cells, "the fins are listed separately")
+	a.Equal([]Candidate{{15, 4}, {24, 4}}, step.Eliminations)
+}
+
+// transpose swaps the rows and columns of a set of restrictions
+func transpose(restrict map[int][]int) map[int][]int {
+	out := make(map[int][]int, len(restrict))
+	for n, vals := range restrict {
+		out[(n%9)*9+n/9] = vals
+	}
+	return out
+}
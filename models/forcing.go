@@ -0,0 +1,459 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// A ForcingKind identifies the source of the assumptions in a forcing
+// chain or net.
+type ForcingKind int
+
+const (
+	// CellForcing assumes each value of a single square in turn.
+	CellForcing ForcingKind = iota
+	// UnitForcing assumes each position of a value within a unit in turn.
+	UnitForcing
+	// ContradictionForcing assumes a single candidate, which leads to a
+	// contradiction, and so must be false.
+	ContradictionForcing
+)
+
+// String implements the fmt.Stringer interface
+func (k ForcingKind) String() string {
+	switch k {
+	case CellForcing:
+		return "Cell"
+	case UnitForcing:
+		return "Unit"
+	case ContradictionForcing:
+		return "Contradiction"
+	default:
+		return "Forcing"
+	}
+}
+
+// An Inference is a single fact derived while following a forcing chain:
+// a candidate that must be placed, or that must be eliminated.
+type Inference struct {
+	Candidate
+	Placed bool `json:"placed"`
+}
+
+// String implements the fmt.Stringer interface, e.g. "r1c2=5" or "r1c2<>5"
+func (i Inference) String() string {
+	op := "<>"
+	if i.Placed {
+		op = "="
+	}
+	return CellName(i.Cell) + op + strconv.Itoa(i.Digit)
+}
+
+// A Branch is the proof that a forcing chain or net follows from a single
+// assumption. Steps lists the inferences that lead from the assumption to
+// the conclusion, in the order that they were derived; each follows from
+// the assumption and the steps before it.
+type Branch struct {
+	Assumption Candidate   `json:"assumption"`
+	Steps      []Inference `json:"steps"`
+}
+
+// String implements the fmt.Stringer interface,
+// e.g. "r1c1=5: r1c2<>5, r2c2=5"
+func (b Branch) String() string {
+	steps := make([]string, len(b.Steps))
+	for i, s := range b.Steps {
+		steps[i] = s.String()
+	}
+	return Inference{b.Assumption, true}.String() + ": " + strings.Join(steps, ", ")
+}
+
+// A ForcingChain describes a conclusion that holds whichever of several
+// assumptions is true, because every assumption implies it.
+//
+// For a cell forcing chain, the assumptions are the values of Cell. For a
+// unit forcing chain, they are the positions of Digit in Unit. For a
+// contradiction, the only assumption leads to a contradiction, and the
+// conclusion is that it is false.
+//
+// A forcing chain only follows implications which have a single cause,
+// such as a bivalue square losing one of its values. A forcing net (Net is
+// true) may also combine several implications, such as a square losing
+// all but one of its values.
+type ForcingChain struct {
+	Kind       ForcingKind
+	Net        bool
+	Cell       int
+	Unit       Unit
+	Digit      int
+	Conclusion Inference
+	// Branches holds the proof of the conclusion for each assumption.
+	Branches     []Branch
+	Eliminations []Candidate
+}
+
+// Name returns the conventional name of the technique,
+// e.g. "Cell Forcing Chain"
+func (f ForcingChain) Name() string {
+	if f.Net {
+		return f.Kind.String() + " Forcing Net"
+	}
+	return f.Kind.String() + " Forcing Chain"
+}
+
+// String implements the fmt.Stringer interface
+func (f ForcingChain) String() string {
+	var b strings.Builder
+	b.WriteString(f.Name())
+	b.WriteString(": ")
+	switch f.Kind {
+	case CellForcing:
+		b.WriteString(CellName(f.Cell))
+	case UnitForcing:
+		b.WriteString(strconv.Itoa(f.Digit))
+		b.WriteString(" in ")
+		b.WriteString(f.Unit.String())
+	case ContradictionForcing:
+		b.WriteString(Inference{f.Branches[0].Assumption, true}.String())
+		b.WriteString(" is impossible")
+	}
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(f.Eliminations))
+	return b.String()
+}
+
+// Proof returns the proof of each branch, one per line.
+func (f ForcingChain) Proof() string {
+	lines := make([]string, len(f.Branches))
+	for i, br := range f.Branches {
+		lines[i] = br.String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// findForcingChain looks for a cell or unit forcing chain, or a chain
+// that leads to a contradiction, within the given limits.
+func (g Grid) findForcingChain(l Limits) (ForcingChain, bool) {
+	return g.findForcing(false, l.chainDepth(), l.Timeout)
+}
+
+// findForcingNet looks for a cell or unit forcing net, or a net that
+// leads to a contradiction, within the given limits.
+func (g Grid) findForcingNet(l Limits) (ForcingChain, bool) {
+	return g.findForcing(true, l.netDepth(), l.Timeout)
+}
+
+// findForcing tries the squares and units with the fewest candidates
+// first, following each assumption for at most depth implications.
+// Gives up, returning false, once the timeout has passed.
+func (g Grid) findForcing(net bool, depth int, timeout time.Duration) (ForcingChain, bool) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	expired := func() bool {
+		return !deadline.IsZero() && time.Now().After(deadline)
+	}
+
+	for k := 2; k <= 9; k++ {
+		for n := 0; n < 81; n++ {
+			sq := g.squares[n]
+			if sq.IsDefined() || sq.Count() != k {
+				continue
+			}
+			if expired() {
+				return ForcingChain{}, false
+			}
+			var assume []Candidate
+			for _, d := range sq.Values() {
+				assume = append(assume, Candidate{n, d})
+			}
+			if f, ok := g.forcing(assume, net, depth); ok {
+				f.Cell = n
+				return f, true
+			}
+		}
+		for u := 0; u < 27; u++ {
+			for d := 1; d <= 9; d++ {
+				cells := g.positions(u, d)
+				if len(cells) != k {
+					continue
+				}
+				if expired() {
+					return ForcingChain{}, false
+				}
+				var assume []Candidate
+				for _, c := range cells {
+					assume = append(assume, Candidate{c, d})
+				}
+				if f, ok := g.forcing(assume, net, depth); ok {
+					if f.Kind == UnitForcing {
+						f.Unit, f.Digit = unitByID(u), d
+					}
+					return f, true
+				}
+			}
+		}
+	}
+	return ForcingChain{}, false
+}
+
+// forcing follows each of the given assumptions, one of which must be
+// true, and looks for a conclusion that they all share. If an assumption
+// leads to a contradiction, then it is eliminated instead.
+func (g Grid) forcing(assume []Candidate, net bool, depth int) (ForcingChain, bool) {
+	kind := UnitForcing
+	if assume[0].Cell == assume[1].Cell {
+		kind = CellForcing
+	}
+
+	branches := make([]*implications, len(assume))
+	for i, a := range assume {
+		br := g.implications(a, net, depth)
+		if br.contradiction != nil {
+			return ForcingChain{
+				Kind:         ContradictionForcing,
+				Net:          net,
+				Cell:         a.Cell,
+				Conclusion:   Inference{a, false},
+				Branches:     []Branch{{a, br.proof(br.contradiction)}},
+				Eliminations: []Candidate{a},
+			}, true
+		}
+		branches[i] = br
+	}
+
+	// look for a placement shared by every branch, then an elimination
+	for _, placed := range [2]bool{true, false} {
+		for _, f := range branches[0].facts[1:] {
+			if f.Placed != placed || !g.isNew(f.Inference) {
+				continue
+			}
+			proofs := make([]Branch, len(branches))
+			shared := true
+			for i, br := range branches {
+				ix, ok := br.index[f.Inference]
+				if !ok {
+					shared = false
+					break
+				}
+				proofs[i] = Branch{assume[i], br.proof([]int{ix})}
+			}
+			if !shared {
+				continue
+			}
+			return ForcingChain{
+				Kind:         kind,
+				Net:          net,
+				Conclusion:   f.Inference,
+				Branches:     proofs,
+				Eliminations: g.eliminationsFor(f.Inference),
+			}, true
+		}
+	}
+	return ForcingChain{}, false
+}
+
+// isNew reports whether an inference would change the grid.
+func (g Grid) isNew(i Inference) bool {
+	sq := g.squares[i.Cell]
+	if i.Placed {
+		return !sq.IsDefined()
+	}
+	return sq.Has(i.Digit)
+}
+
+// eliminationsFor returns the candidates removed by an inference: the
+// candidate itself, or for a placement, the other values of the square.
+func (g Grid) eliminationsFor(i Inference) []Candidate {
+	if !i.Placed {
+		return []Candidate{i.Candidate}
+	}
+	var elims []Candidate
+	for _, d := range g.squares[i.Cell].Values() {
+		if d != i.Digit {
+			elims = append(elims, Candidate{i.Cell, d})
+		}
+	}
+	return elims
+}
+
+// A fact is an inference made while following an assumption, along with
+// the earlier facts that caused it.
+type fact struct {
+	Inference
+	causes []int
+	level  int
+}
+
+// implications holds everything that follows from a single assumption.
+type implications struct {
+	g     Grid
+	facts []fact
+	index map[Inference]int
+	// contradiction holds the facts that together are impossible, if any
+	contradiction []int
+}
+
+// implications follows the consequences of placing candidate a, for at
+// most depth levels. Unless net is true, it only follows implications that
+// have a single cause.
+func (g Grid) implications(a Candidate, net bool, depth int) *implications {
+	im := &implications{
+		g:     *g.Clone(),
+		index: make(map[Inference]int),
+	}
+	im.add(fact{Inference: Inference{a, true}})
+
+	for next := 0; next < len(im.facts) && im.contradiction == nil; next++ {
+		f := im.facts[next]
+		if f.level >= depth {
+			continue
+		}
+		if f.Placed {
+			im.place(next)
+		} else {
+			im.eliminated(next, net)
+		}
+	}
+	return im
+}
+
+func (im *implications) add(f fact) int {
+	ix := len(im.facts)
+	im.facts = append(im.facts, f)
+	im.index[f.Inference] = ix
+	return ix
+}
+
+// place removes the other values of the placed square, and the placed
+// value from its peers.
+func (im *implications) place(ix int) {
+	f := im.facts[ix]
+	c, d := f.Cell, f.Digit
+	for _, e := range im.g.squares[c].Values() {
+		if e != d {
+			im.eliminate(Candidate{c, e}, ix)
+		}
+	}
+	for _, p := range peers[c] {
+		if im.g.squares[p].Has(d) {
+			im.eliminate(Candidate{p, d}, ix)
+		}
+		if im.contradiction != nil {
+			return
+		}
+	}
+}
+
+func (im *implications) eliminate(c Candidate, cause int) {
+	sq := im.g.squares[c.Cell]
+	if !sq.Has(c.Digit) {
+		return
+	}
+	im.g.squares[c.Cell] = sq &^ squareEnum[c.Digit]
+	im.add(fact{
+		Inference: Inference{c, false},
+		causes:    []int{cause},
+		level:     im.facts[cause].level + 1,
+	})
+}
+
+// eliminated looks for squares and units that have been left with only
+// one place for a value, or with none.
+func (im *implications) eliminated(ix int, net bool) {
+	f := im.facts[ix]
+	c, d := f.Cell, f.Digit
+
+	// the values eliminated from the square
+	var causes []int
+	for e := 1; e <= 9; e++ {
+		if j, ok := im.index[Inference{Candidate{c, e}, false}]; ok {
+			causes = append(causes, j)
+		}
+	}
+	switch sq := im.g.squares[c]; {
+	case sq == none:
+		im.contradiction = causes
+		return
+	case sq.Count() == 1:
+		im.infer(Inference{Candidate{c, sq.Value()}, true}, causes, net)
+	}
+
+	for _, u := range cellUnits[c] {
+		var (
+			pos    []int
+			causes []int
+			placed bool
+		)
+		for _, n := range unitCells[u] {
+			sq := im.g.squares[n]
+			switch {
+			case sq == squareEnum[d]:
+				placed = true
+			case sq.Has(d):
+				pos = append(pos, n)
+			default:
+				if j, ok := im.index[Inference{Candidate{n, d}, false}]; ok {
+					causes = append(causes, j)
+				}
+			}
+		}
+		switch {
+		case placed:
+		case len(pos) == 0:
+			im.contradiction = causes
+			return
+		case len(pos) == 1:
+			im.infer(Inference{Candidate{pos[0], d}, true}, causes, net)
+		}
+	}
+}
+
+// infer adds a placement with the given causes, unless it is already
+// known, or it has more than one cause and net is false.
+func (im *implications) infer(i Inference, causes []int, net bool) {
+	if _, ok := im.index[i]; ok || (!net && len(causes) > 1) {
+		return
+	}
+	level := 0
+	for _, j := range causes {
+		if l := im.facts[j].level; l > level {
+			level = l
+		}
+	}
+	im.add(fact{Inference: i, causes: causes, level: level + 1})
+}
+
+// proof returns the facts that lead to the given ones, including them,
+// in the order that they were derived. The assumption is left out.
+func (im *implications) proof(ixs []int) []Inference {
+	seen := make(map[int]bool)
+	var visit func(int)
+	visit = func(j int) {
+		if seen[j] {
+			return
+		}
+		seen[j] = true
+		for _, k := range im.facts[j].causes {
+			visit(k)
+		}
+	}
+	for _, j := range ixs {
+		visit(j)
+	}
+	order := make([]int, 0, len(seen))
+	for j := range seen {
+		if j != 0 {
+			order = append(order, j)
+		}
+	}
+	sort.Ints(order)
+	steps := make([]Inference, len(order))
+	for i, j := range order {
+		steps[i] = im.facts[j].Inference
+	}
+	return steps
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// a puzzle that needs a forcing chain once the subset and intersection
+// passes have been applied
+const forcingPuzzle = "000000023600010000000400000000080700502000000000000100080203000010000640000500000"
+
+func forcingGrid(t *testing.T) Grid {
+	t.Helper()
+	g := NewGrid([]byte(forcingPuzzle))
+	require.NoError(t, g.NormalizeWith(BasicPasses|SubsetPasses|IntersectionPasses))
+	return g
+}
+
+func TestFindForcingChain(t *testing.T) {
+	g := forcingGrid(t)
+
+	got, ok := g.findForcingChain(Limits{})
+	require.True(t, ok)
+	assert.Equal(t, ForcingChain{
+		Kind:       CellForcing,
+		Cell:       0,
+		Conclusion: Inference{Candidate{47, 9}, false},
+		Branches: []Branch{
+			{Candidate{0, 1}, []Inference{
+				{Candidate{27, 1}, false}, {Candidate{27, 9}, true}, {Candidate{47, 9}, false},
+			}},
+			{Candidate{0, 8}, []Inference{
+				{Candidate{2, 8}, false}, {Candidate{47, 8}, true}, {Candidate{47, 9}, false},
+			}},
+		},
+		Eliminations: []Candidate{{47, 9}},
+	}, got)
+	assert.Equal(t, "Cell Forcing Chain: r1c1 => r6c3<>9", got.String())
+	assert.Equal(t, "r1c1=1: r4c1<>1, r4c1=9, r6c3<>9\n"+
+		"r1c1=8: r1c3<>8, r6c3=8, r6c3<>9", got.Proof())
+}
+
+func TestForcingChainStep(t *testing.T) {
+	g := forcingGrid(t)
+	step, ok := BuiltinTechniques(PassForcingChains)[0].Apply(&g)
+	require.True(t, ok)
+
+	// the proof survives a round trip through a JSON solve log
+	b, err := json.Marshal(step)
+	require.NoError(t, err)
+	var got Step
+	require.NoError(t, json.Unmarshal(b, &got))
+
+	a := assert.New(t)
+	a.Equal(step, got)
+	a.Equal([]Branch{
+		{Candidate{0, 1}, []Inference{
+			{Candidate{27, 1}, false}, {Candidate{27, 9}, true}, {Candidate{47, 9}, false},
+		}},
+		{Candidate{0, 8}, []Inference{
+			{Candidate{2, 8}, false}, {Candidate{47, 8}, true}, {Candidate{47, 9}, false},
+		}},
+	}, got.Proof)
+	a.Equal("r1c1=1: r4c1<>1, r4c1=9, r6c3<>9", got.Proof[0].String())
+}
+
+func TestFindForcingContradiction(t *testing.T) {
+	// r1c1=5 leaves nowhere for 5 in row 2
+	g := candidateGrid(map[int][]int{0: {5, 6}, 9: {5, 6}, 10: {5, 7}})
+	for c := 11; c < 18; c++ {
+		g.squares[c] &^= squareEnum[5]
+	}
+
+	got, ok := g.findForcingChain(Limits{})
+	require.True(t, ok)
+	assert.Equal(t, ContradictionForcing, got.Kind)
+	assert.Equal(t, []Candidate{{0, 5}}, got.Eliminations)
+	assert.Equal(t, "Contradiction Forcing Chain: r1c1=5 is impossible => r1c1<>5", got.String())
+	assert.Equal(t, "r1c1=5: r2c1<>5, r2c2<>5", got.Proof())
+}
+
+func TestFindForcingLimits(t *testing.T) {
+	g := forcingGrid(t)
+
+	_, ok := g.findForcing(false, 0, 0)
+	assert.False(t, ok, "no implications are followed at depth 0")
+
+	_, ok = g.findForcing(true, DefaultNetDepth, time.Nanosecond)
+	assert.False(t, ok, "the search should time out")
+
+	got, ok := g.findForcing(true, DefaultNetDepth, 0)
+	require.True(t, ok)
+	assert.True(t, got.Net)
+	assert.Equal(t, "Cell Forcing Net", got.Name())
+}
+
+func TestBuiltinTechniquesWithLimits(t *testing.T) {
+	a := assert.New(t)
+
+	g := forcingGrid(t)
+	_, ok := BuiltinTechniquesWith(PassForcingChains, Limits{ChainDepth: 1})[0].Apply(&g)
+	a.False(ok, "no chain is that short")
+	step, ok := BuiltinTechniquesWith(PassForcingChains, Limits{})[0].Apply(&g)
+	if a.True(ok) {
+		a.Equal("Cell Forcing Chain: r1c1 => r6c3<>9", step.Description)
+	}
+
+	// the default limits do not depend on the clock
+	a.Zero(Limits{}.Timeout)
+	a.Equal(DefaultChainDepth, Limits{}.chainDepth())
+	a.Equal(DefaultNetDepth, Limits{}.netDepth())
+}
@@ -1,36 +1,22 @@
 package models
 
-import (
-	"errors"
-	"strings"
-)
+import "strings"
 
 type Grid struct {
 	squares *[81]Square
+	// unique is true if the puzzle is known to have exactly one solution,
+	// which enables the UniquenessPasses.
+	unique bool
 }
 
 // NewGrid initializes a sudoku grid using the given input.
 // Each square should be given as a digit (if the square is defined).
 // If the square is undefined, it should be given as either '0' or '.'
-// All other characters are ignored.
+// All other characters are ignored, as are any squares after the 81st.
+// If fewer than 81 squares are given, the rest are undefined.
+// Use ParseGrid to reject input that is not a well formed grid.
 func NewGrid(in []byte) Grid {
-	g := Grid{
-		squares: new([81]Square),
-	}
-	i := 0
-	for _, ch := range in {
-		switch ch {
-		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
-			g.squares[i] = NewSquare(int(ch - '0'))
-			i++
-		case '.':
-			g.squares[i] = NewSquare(0)
-			i++
-		default:
-			continue
-		}
-	}
-
+	g, _ := parseGrid(in, false)
 	return g
 }
 
@@ -38,7 +24,7 @@ func NewGrid(in []byte) Grid {
 func (g Grid) Clone() *Grid {
 	sq := new([81]Square)
 	copy(sq[:], g.squares[:])
-	return &Grid{sq}
+	return &Grid{squares: sq, unique: g.unique}
 }
 
 // String implements the fmt.Stringer interface
@@ -100,217 +86,176 @@ func (g Grid) Set(i, k int) {
 	g.squares[i] = squareEnum[k]
 }
 
+// A Pass is one of the rules that Normalize can apply to refine a grid.
+// Passes can be combined with the | operator.
+type Pass uint64
+
+const (
+	// PassReduce excludes values that are defined elsewhere in the same
+	// row, column or block (naked singles).
+	PassReduce Pass = 1 << iota
+	// PassDeduce defines squares that hold the only possible position for
+	// a value in their row, column or block (hidden singles).
+	PassDeduce
+	// PassNakedPairs, PassNakedTriples and PassNakedQuads remove values
+	// held by a naked subset from the rest of its unit.
+	PassNakedPairs
+	PassNakedTriples
+	PassNakedQuads
+	// PassHiddenPairs, PassHiddenTriples and PassHiddenQuads remove all
+	// other values from the squares of a hidden subset.
+	PassHiddenPairs
+	PassHiddenTriples
+	PassHiddenQuads
+	// PassPointing removes a value from a row or column when, within some
+	// block, the value can only go in that row or column.
+	PassPointing
+	// PassBoxLine removes a value from a block when, within some row or
+	// column, the value can only go in that block.
+	PassBoxLine
+	// PassXWing, PassSwordfish and PassJellyfish remove a value using
+	// basic fish of size 2, 3 and 4.
+	PassXWing
+	PassSwordfish
+	PassJellyfish
+	// PassFinnedXWing, PassFinnedSwordfish and PassFinnedJellyfish remove
+	// a value using finned and sashimi fish of size 2, 3 and 4.
+	PassFinnedXWing
+	PassFinnedSwordfish
+	PassFinnedJellyfish
+	// PassXYWing, PassXYZWing, PassWWing and PassWXYZWing remove a value
+	// using the wing patterns.
+	PassXYWing
+	PassXYZWing
+	PassWWing
+	PassWXYZWing
+	// PassSkyscraper, PassTwoStringKite, PassEmptyRectangle and
+	// PassTurbotFish remove a value using single digit patterns.
+	PassSkyscraper
+	PassTwoStringKite
+	PassEmptyRectangle
+	PassTurbotFish
+	// PassSimpleColoring removes a value using color traps and color wraps
+	// on the conjugate pairs of a single digit.
+	PassSimpleColoring
+	// PassMultiColoring removes a value using two clusters of conjugate
+	// pairs on the same digit.
+	PassMultiColoring
+	// PassXChain, PassXYChain and PassAIC remove values using alternating
+	// inference chains of at most Limits.MaxChainLength candidates.
+	// PassAIC also finds continuous and discontinuous nice loops.
+	PassXChain
+	PassXYChain
+	PassAIC
+	// PassUniqueRectangles, PassHiddenUniqueRectangles and PassBUGPlusOne
+	// remove values that would allow a second solution. They only apply
+	// to grids that assume a unique solution; see Grid.AssumeUnique.
+	PassUniqueRectangles
+	PassHiddenUniqueRectangles
+	PassBUGPlusOne
+	// PassALSXZ, PassALSXYWing and PassDeathBlossom remove values using
+	// almost locked sets.
+	PassALSXZ
+	PassALSXYWing
+	PassDeathBlossom
+	// PassSueDeCoq removes values using a locked set spread over the
+	// intersection of a block and a row or column.
+	PassSueDeCoq
+	// PassForcingChains and PassForcingNets remove values that are ruled
+	// out whichever value a square (or position of a value in a unit)
+	// takes, within the given Limits. They are a last resort
+	// before trial and error.
+	PassForcingChains
+	PassForcingNets
+
+	// BasicPasses are the passes applied by Normalize.
+	BasicPasses = PassReduce | PassDeduce
+	// SubsetPasses are the naked and hidden subset passes.
+	SubsetPasses = PassNakedPairs | PassNakedTriples | PassNakedQuads |
+		PassHiddenPairs | PassHiddenTriples | PassHiddenQuads
+	// IntersectionPasses are the locked candidate passes.
+	IntersectionPasses = PassPointing | PassBoxLine
+	// AllPasses enables every pass.
+	AllPasses = ^Pass(0)
+	// FishPasses are the basic, finned and sashimi fish passes.
+	FishPasses = PassXWing | PassSwordfish | PassJellyfish |
+		PassFinnedXWing | PassFinnedSwordfish | PassFinnedJellyfish
+	// WingPasses are the XY-Wing, XYZ-Wing, W-Wing and WXYZ-Wing passes.
+	WingPasses = PassXYWing | PassXYZWing | PassWWing | PassWXYZWing
+	// SingleDigitPasses are the Skyscraper, 2-String Kite, Empty Rectangle
+	// and Turbot Fish passes.
+	SingleDigitPasses = PassSkyscraper | PassTwoStringKite |
+		PassEmptyRectangle | PassTurbotFish
+	// ColoringPasses are the simple coloring and multi-coloring passes.
+	ColoringPasses = PassSimpleColoring | PassMultiColoring
+	// ChainPasses are the X-Chain, XY-Chain and AIC passes.
+	ChainPasses = PassXChain | PassXYChain | PassAIC
+	// UniquenessPasses are the passes that rely on the puzzle having a
+	// unique solution.
+	UniquenessPasses = PassUniqueRectangles | PassHiddenUniqueRectangles |
+		PassBUGPlusOne
+	// ALSPasses are the ALS-XZ, ALS-XY-Wing and Death Blossom passes.
+	ALSPasses = PassALSXZ | PassALSXYWing | PassDeathBlossom
+	// ForcingPasses are the forcing chain and forcing net passes.
+	ForcingPasses = PassForcingChains | PassForcingNets
+)
+
+// builtins lists the technique behind each pass, in the order that
+// NormalizeWith tries them: cheaper techniques first, so that the expensive
+// ones only run once the cheaper ones have nothing left to do. Difficulties
+// follow the Sudoku Explainer scale where it has an equivalent technique.
+var builtins = []*builtin{
+	{PassDeduce, "Hidden Single", 1.2, func(g Grid, _ Limits) (pattern, bool) { return g.findHiddenSingle() }},
+	{PassReduce, "Naked Single", 2.3, func(g Grid, _ Limits) (pattern, bool) { return g.findNakedSingle() }},
+	{PassPointing, "Pointing", 2.6, func(g Grid, _ Limits) (pattern, bool) { return g.findPointing() }},
+	{PassBoxLine, "Box/Line Reduction", 2.8, func(g Grid, _ Limits) (pattern, bool) { return g.findBoxLine() }},
+	{PassNakedPairs, "Naked Pair", 3.0, func(g Grid, _ Limits) (pattern, bool) { return g.findNakedSubset(2) }},
+	{PassXWing, "X-Wing", 3.2, func(g Grid, _ Limits) (pattern, bool) { return g.findFish(2, false) }},
+	{PassHiddenPairs, "Hidden Pair", 3.4, func(g Grid, _ Limits) (pattern, bool) { return g.findHiddenSubset(2) }},
+	{PassNakedTriples, "Naked Triple", 3.6, func(g Grid, _ Limits) (pattern, bool) { return g.findNakedSubset(3) }},
+	{PassSwordfish, "Swordfish", 3.8, func(g Grid, _ Limits) (pattern, bool) { return g.findFish(3, false) }},
+	{PassHiddenTriples, "Hidden Triple", 4.0, func(g Grid, _ Limits) (pattern, bool) { return g.findHiddenSubset(3) }},
+	{PassSkyscraper, "Skyscraper", 4.0, func(g Grid, _ Limits) (pattern, bool) { return g.findSkyscraper() }},
+	{PassTwoStringKite, "2-String Kite", 4.1, func(g Grid, _ Limits) (pattern, bool) { return g.findTwoStringKite() }},
+	{PassTurbotFish, "Turbot Fish", 4.2, func(g Grid, _ Limits) (pattern, bool) { return g.findTurbotFish() }},
+	{PassEmptyRectangle, "Empty Rectangle", 4.2, func(g Grid, _ Limits) (pattern, bool) { return g.findEmptyRectangle() }},
+	{PassSimpleColoring, "Simple Coloring", 4.2, func(g Grid, _ Limits) (pattern, bool) { return g.findSimpleColoring() }},
+	{PassXYWing, "XY-Wing", 4.2, func(g Grid, _ Limits) (pattern, bool) { return g.findXYWing() }},
+	{PassXYZWing, "XYZ-Wing", 4.4, func(g Grid, _ Limits) (pattern, bool) { return g.findXYZWing() }},
+	{PassWWing, "W-Wing", 4.4, func(g Grid, _ Limits) (pattern, bool) { return g.findWWing() }},
+	{PassUniqueRectangles, "Unique Rectangle", 4.5, func(g Grid, _ Limits) (pattern, bool) { return g.findUniqueRectangle() }},
+	{PassHiddenUniqueRectangles, "Hidden Unique Rectangle", 4.6, func(g Grid, _ Limits) (pattern, bool) { return g.findHiddenUniqueRectangle() }},
+	{PassFinnedXWing, "Finned X-Wing", 4.7, func(g Grid, _ Limits) (pattern, bool) { return g.findFish(2, true) }},
+	{PassNakedQuads, "Naked Quad", 5.0, func(g Grid, _ Limits) (pattern, bool) { return g.findNakedSubset(4) }},
+	{PassJellyfish, "Jellyfish", 5.2, func(g Grid, _ Limits) (pattern, bool) { return g.findFish(4, false) }},
+	{PassHiddenQuads, "Hidden Quad", 5.4, func(g Grid, _ Limits) (pattern, bool) { return g.findHiddenSubset(4) }},
+	{PassWXYZWing, "WXYZ-Wing", 5.5, func(g Grid, _ Limits) (pattern, bool) { return g.findWXYZWing() }},
+	{PassMultiColoring, "Multi-Coloring", 5.5, func(g Grid, _ Limits) (pattern, bool) { return g.findMultiColoring() }},
+	{PassFinnedSwordfish, "Finned Swordfish", 5.5, func(g Grid, _ Limits) (pattern, bool) { return g.findFish(3, true) }},
+	{PassFinnedJellyfish, "Finned Jellyfish", 5.6, func(g Grid, _ Limits) (pattern, bool) { return g.findFish(4, true) }},
+	{PassBUGPlusOne, "BUG+1", 5.6, func(g Grid, _ Limits) (pattern, bool) { return g.findBUGPlusOne() }},
+	{PassSueDeCoq, "Sue de Coq", 5.7, func(g Grid, _ Limits) (pattern, bool) { return g.findSueDeCoq() }},
+	{PassXChain, "X-Chain", 6.5, func(g Grid, l Limits) (pattern, bool) { return g.findXChain(l.maxChainLength()) }},
+	{PassXYChain, "XY-Chain", 6.6, func(g Grid, l Limits) (pattern, bool) { return g.findXYChain(l.maxChainLength()) }},
+	{PassAIC, "AIC", 7.0, func(g Grid, l Limits) (pattern, bool) { return g.findAIC(l.maxChainLength()) }},
+	{PassALSXZ, "ALS-XZ", 7.5, func(g Grid, l Limits) (pattern, bool) { return g.findALSXZ(l.maxALSSize()) }},
+	{PassALSXYWing, "ALS-XY-Wing", 7.8, func(g Grid, l Limits) (pattern, bool) { return g.findALSXYWing(l.maxALSSize()) }},
+	{PassDeathBlossom, "Death Blossom", 8.0, func(g Grid, l Limits) (pattern, bool) { return g.findDeathBlossom(l.maxALSSize()) }},
+	{PassForcingChains, "Forcing Chain", 8.3, func(g Grid, l Limits) (pattern, bool) { return g.findForcingChain(l) }},
+	{PassForcingNets, "Forcing Net", 9.0, func(g Grid, l Limits) (pattern, bool) { return g.findForcingNet(l) }},
+}
+
 // Normalize applies logic to the grid, identifying possible and impossible
 // values for each square without using trial and error.
+// Only the BasicPasses are applied.
 // Returns an error if the grid is invalid.
 func (g Grid) Normalize() error {
-	for {
-		delta := 0
-
-		ids, err := g.reduce()
-		if err != nil {
-			return err
-		}
-		delta += len(ids)
-
-		ids, err = g.deduce()
-		if err != nil {
-			return err
-		}
-		delta += len(ids)
-
-		if delta == 0 {
-			break
-		}
-	}
-	return nil
-}
-
-// reduce performs one round of refinement based on the process of
-// set subtraction.  That is, the process of excluding from each square the
-// values that are definitely assigned within the same row, column or block.
-// Returns a list of square indices that are now defined that weren't before.
-// Returns an error if any square has been reduced to the point where it cannot
-// be any possible value.
-func (g Grid) reduce() ([]int, error) {
-	newlyDefined := make([]int, 0, 8)
-	for i := 0; i < 81; i++ {
-		didUpdate, err := g.reduceSquare(i)
-		if err != nil {
-			return nil, err
-		}
-		if didUpdate {
-			newlyDefined = append(newlyDefined, i)
-		}
-	}
-	return newlyDefined, nil
-}
-
-// reduceSquare refines the nth square for this grid by excluding candidate
-// values are already defined in the same row, column, or 3x3 block.
-// returns true if the square is now defined and wasn't before.
-func (g Grid) reduceSquare(n int) (bool, error) {
-	if g.squares[n].IsDefined() {
-		return false, nil
-	}
-	var (
-		row   = g.getRow(n)
-		col   = g.getCol(n)
-		block = g.getBlock(n)
-	)
-	sq := g.squares[n]
-	sq = excludeDefined(sq, row)
-	sq = excludeDefined(sq, col)
-	sq = excludeDefined(sq, block)
-
-	if sq == none {
-		return false, errors.New("no possible value for this square")
-	}
-
-	g.squares[n] = sq
-	return sq.IsDefined(), nil
-}
-
-// excludeDefined refines the set of values of the given square
-// by any strongly defined squares in the group
-func excludeDefined(sq Square, others []Square) Square {
-	for _, other := range others {
-		if other.IsDefined() {
-			sq = sq &^ other
-		}
-	}
-	return sq
-}
-
-// deduce performs one round of refinement based on the process of deduction.
-// That is, the process of setting a square if it is the ONLY
-// square in its row/column/block which can have a particular value.
-// Returns a list of square indices that are now defined that weren't before.
-// If there is more than one value which a square *must* be, then
-// we return an error
-func (g Grid) deduce() ([]int, error) {
-	newlyDefined := make([]int, 0, 8)
-	for i := 0; i < 81; i++ {
-		isFound, err := g.deduceSquare(i)
-		if err != nil {
-			return nil, err
-		}
-		if isFound {
-			newlyDefined = append(newlyDefined, i)
-		}
-	}
-	return newlyDefined, nil
+	return g.NormalizeWith(BasicPasses)
 }
 
-// deduceSquare is used to detect cases where there is some value that
-// no other square in the row / column / block can possibly be.
-// Returns true if the square is now defined and wasn't before.
-// Returns an error if this square would need to have more than one value
-// to satisfy the row / column / block requirements.
-func (g Grid) deduceSquare(n int) (bool, error) {
-	if g.squares[n].IsDefined() {
-		return false, nil
-	}
-	var (
-		row   = g.getRow(n)
-		col   = g.getCol(n)
-		block = g.getBlock(n)
-	)
-
-	need, err := findMissing(row)
-	if err != nil {
-		return false, err
-	}
-	if need != none {
-		g.squares[n] = need
-		return true, nil
-	}
-
-	need, err = findMissing(col)
-	if err != nil {
-		return false, err
-	}
-	if need != none {
-		g.squares[n] = need
-		return true, nil
-	}
-
-	need, err = findMissing(block)
-	if err != nil {
-		return false, err
-	}
-	if need != none {
-		g.squares[n] = need
-		return true, nil
-	}
-
-	return false, nil
-}
-
-// findMissing looks for a single value which none of the given squares can be.
-// Returns an error if there is more than one value missing.
-func findMissing(group []Square) (Square, error) {
-	exists := none
-	for _, sq := range group {
-		exists |= sq
-	}
-	notExists := any &^ exists
-
-	if notExists != none && !notExists.IsDefined() {
-		return notExists, errors.New("more than one value is missing")
-	}
-
-	return notExists, nil
-}
-
-// getRow gets the other squares from the same row as the square at index n.
-// Excludes square n from the list.
-func (g Grid) getRow(n int) []Square {
-	row := make([]Square, 8)
-	j := 0
-	start := (n / 9) * 9
-	for i := start; i < start+9; i++ {
-		if i == n {
-			continue
-		}
-		row[j] = g.squares[i]
-		j++
-	}
-	return row
-}
-
-// getCol gets the other squares from the same column as the square at index n.
-// Excludes square n from the list.
-func (g Grid) getCol(n int) []Square {
-	col := make([]Square, 8)
-	j := 0
-	start := n % 9
-	for i := start; i < 81; i += 9 {
-		if i == n {
-			continue
-		}
-		col[j] = g.squares[i]
-		j++
-	}
-	return col
-}
-
-// getBlock gets the other squares from the same 3x3 block as the square at n.
-// Excludes square n from the list.
-func (g Grid) getBlock(n int) []Square {
-	// r0, c0 is the top left corner of this 3x3 block:
-	r0, c0 := (n/27)*27, ((n%9)/3)*3
-	block := make([]Square, 8)
-	b := 0
-	for c := 0; c < 3; c++ {
-		for r := 0; r < 3; r++ {
-			ix := r0 + r*9 + c0 + c
-			if ix == n {
-				continue
-			}
-			block[b] = g.squares[ix]
-			b++
-		}
-	}
-	return block
+// NormalizeWith is like Normalize, but applies the techniques behind the
+// given passes, with the default Limits. See Propagate.
+// Returns an error if the grid is invalid.
+func (g Grid) NormalizeWith(p Pass) error {
+	return g.Propagate(BuiltinTechniques(p))
 }
@@ -173,23 +173,37 @@ func TestRefineOne(t *testing.T) {
 			t.Parallel()
 			r := require.New(t)
 
-			tc.grid.reduceSquare(tc.n)
+			grid := tc.grid.Clone()
+			r.NoError(grid.Apply(placePeers(*grid, tc.n)))
 
-			got := tc.grid.squares[tc.n]
+			got := grid.squares[tc.n]
 			r.Equal(tc.want, got)
 		})
 	}
 }
 
+// placePeers returns the step that places the defined peers of square n,
+// which leaves n with only the values that its peers allow.
+func placePeers(g Grid, n int) Step {
+	var s Step
+	for _, p := range peers[n] {
+		if sq := g.squares[p]; sq.IsDefined() {
+			s.Placements = append(s.Placements, Candidate{p, sq.Value()})
+		}
+	}
+	return s
+}
+
 func BenchmarkRefineOne(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		for _, tc := range casesRefineOne {
-			tc.grid.reduceSquare(tc.n)
+			grid := tc.grid.Clone()
+			_ = grid.Apply(placePeers(*grid, tc.n))
 		}
 	}
 }
 
-var casesRefine = []struct {
+var casesNormalize = []struct {
 	name string
 	in   string
 	want [81][]int
@@ -250,26 +264,15 @@ var casesRefine = []struct {
 	},
 }
 
-func TestRefine(t *testing.T) {
-	for _, tc := range casesRefine {
+// TestNormalize checks the values left in every square once the
+// BasicPasses can refine the grid no further.
+func TestNormalize(t *testing.T) {
+	for _, tc := range casesNormalize {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 			grid := NewGrid([]byte(tc.in))
-			numLoops := 0
-			for {
-				numChanges := 0
-				changes, err := grid.reduce()
-				require.NoError(t, err)
-				numChanges += len(changes)
-				changes, err = grid.deduce()
-				require.NoError(t, err)
-				numChanges += len(changes)
-				if numChanges == 0 {
-					break
-				}
-				numLoops++
-			}
+			require.NoError(t, grid.Normalize())
 			want := rebuildSquares(tc.want)
 			require.Equal(t, *want, *grid.squares)
 		})
@@ -304,19 +307,31 @@ func TestDeduceOne(t *testing.T) {
 		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
-			grid := Grid{rebuildSquares(tc.grid)}
-			got, err := grid.deduceSquare(tc.n)
-			require.NoError(t, err)
-			assert.Equal(t, tc.didChange, got)
+			grid := Grid{squares: rebuildSquares(tc.grid)}
+			assert.Equal(t, tc.didChange, deduces(grid, tc.n))
 		})
 	}
 }
 
+// deduces reports whether one of the Hidden Single steps in g, applied one
+// at a time, places a value in square n.
+func deduces(g Grid, n int) bool {
+	hidden := BuiltinTechniques(PassDeduce)[0]
+	for {
+		s, ok := hidden.Apply(&g)
+		if !ok {
+			return false
+		}
+		if s.Placements[0].Cell == n {
+			return true
+		}
+	}
+}
+
 func BenchmarkDeduceOne(b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		for _, tc := range casesDeduceOne {
-			grid := Grid{rebuildSquares(tc.grid)}
-			grid.deduceSquare(tc.n)
+			deduces(Grid{squares: rebuildSquares(tc.grid)}, tc.n)
 		}
 	}
 }
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LockedCandidates describes a digit whose candidates in one unit (Base)
+// all lie within its intersection with another unit (Cover).
+// Since the digit must go somewhere in the intersection, it can be removed
+// from the rest of the cover unit.
+//
+// When the base is a block this is a pointing pair or triple; when the base
+// is a row or column it is a box/line reduction.
+type LockedCandidates struct {
+	Digit        int
+	Base         Unit
+	Cover        Unit
+	Cells        []int
+	Eliminations []Candidate
+}
+
+// Name returns the conventional name of the pattern, e.g. "Pointing Pair"
+func (l LockedCandidates) Name() string {
+	if l.Base.Kind != Block {
+		return "Box/Line Reduction"
+	}
+	if len(l.Cells) == 3 {
+		return "Pointing Triple"
+	}
+	return "Pointing Pair"
+}
+
+// String implements the fmt.Stringer interface
+func (l LockedCandidates) String() string {
+	var b strings.Builder
+	b.WriteString(l.Name())
+	b.WriteString(": ")
+	b.WriteString(strconv.Itoa(l.Digit))
+	b.WriteString(" in ")
+	b.WriteString(l.Base.String())
+	b.WriteString(" is locked to ")
+	b.WriteString(l.Cover.String())
+	b.WriteString(" at ")
+	b.WriteString(formatCells(l.Cells))
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(l.Eliminations))
+	return b.String()
+}
+
+// findPointing looks for a digit whose candidates within a block all lie
+// in the same row or column, and which can be removed from the rest of
+// that row or column.
+func (g Grid) findPointing() (LockedCandidates, bool) {
+	for b := 18; b < 27; b++ {
+		for d := 1; d <= 9; d++ {
+			cells := g.positions(b, d)
+			if len(cells) < 2 {
+				continue
+			}
+			// cellUnits[n][0] is the row of n, and cellUnits[n][1] its column
+			for k := 0; k < 2; k++ {
+				line := cellUnits[cells[0]][k]
+				if !allInUnit(cells, k, line) {
+					continue
+				}
+				if l, ok := g.lockedCandidates(d, b, line, cells); ok {
+					return l, true
+				}
+			}
+		}
+	}
+	return LockedCandidates{}, false
+}
+
+// findBoxLine looks for a digit whose candidates within a row or column
+// all lie in the same block, and which can be removed from the rest of
+// that block.
+func (g Grid) findBoxLine() (LockedCandidates, bool) {
+	for line := 0; line < 18; line++ {
+		for d := 1; d <= 9; d++ {
+			cells := g.positions(line, d)
+			if len(cells) < 2 {
+				continue
+			}
+			block := cellUnits[cells[0]][2]
+			if !allInUnit(cells, 2, block) {
+				continue
+			}
+			if l, ok := g.lockedCandidates(d, line, block, cells); ok {
+				return l, true
+			}
+		}
+	}
+	return LockedCandidates{}, false
+}
+
+// lockedCandidates builds the pattern for digit d, which in the base unit
+// can only go in the given cells, all of which are also in the cover unit.
+// Returns false if there is nothing to eliminate.
+func (g Grid) lockedCandidates(d, base, cover int, cells []int) (LockedCandidates, bool) {
+	var elims []Candidate
+	for _, c := range unitCells[cover] {
+		if containsInt(cells, c) || !g.squares[c].Has(d) || g.squares[c].IsDefined() {
+			continue
+		}
+		elims = append(elims, Candidate{c, d})
+	}
+	if len(elims) == 0 {
+		return LockedCandidates{}, false
+	}
+	return LockedCandidates{
+		Digit:        d,
+		Base:         unitByID(base),
+		Cover:        unitByID(cover),
+		Cells:        cells,
+		Eliminations: elims,
+	}, true
+}
+
+// positions returns the undefined squares in the unit with the given id
+// that could hold the value d. Returns nil if d is already defined in the
+// unit.
+func (g Grid) positions(u, d int) []int {
+	var cells []int
+	for _, c := range unitCells[u] {
+		sq := g.squares[c]
+		if !sq.Has(d) {
+			continue
+		}
+		if sq.IsDefined() {
+			return nil
+		}
+		cells = append(cells, c)
+	}
+	return cells
+}
+
+// allInUnit reports whether the k'th unit (0 for row, 1 for column,
+// 2 for block) of every cell is the unit with the given id.
+func allInUnit(cells []int, k, u int) bool {
+	for _, c := range cells {
+		if cellUnits[c][k] != u {
+			return false
+		}
+	}
+	return true
+}
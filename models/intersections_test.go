@@ -0,0 +1,135 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// without returns the values 1-9, except for those given
+func without(vals ...int) []int {
+	var out []int
+	for v := 1; v <= 9; v++ {
+		if !containsInt(vals, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func TestFindPointing(t *testing.T) {
+	tt := []struct {
+		name  string
+		grid  Grid
+		want  LockedCandidates
+		title string
+	}{
+		{
+			name: "pair in a row",
+			// in block 1, 5 can only go in r1c1 or r1c2
+			grid: candidateGrid(map[int][]int{
+				2: without(5), 9: without(5), 10: without(5), 11: without(5),
+				18: without(5), 19: without(5), 20: without(5),
+			}),
+			want: LockedCandidates{
+				Digit: 5,
+				Base:  Unit{Block, 0},
+				Cover: Unit{Row, 0},
+				Cells: []int{0, 1},
+				Eliminations: []Candidate{
+					{3, 5}, {4, 5}, {5, 5}, {6, 5}, {7, 5}, {8, 5},
+				},
+			},
+			title: "Pointing Pair",
+		},
+		{
+			name: "triple in a column",
+			// in block 9, 3 can only go in column 9
+			grid: candidateGrid(map[int][]int{
+				60: without(3), 61: without(3), 69: without(3),
+				70: without(3), 78: without(3), 79: without(3),
+			}),
+			want: LockedCandidates{
+				Digit: 3,
+				Base:  Unit{Block, 8},
+				Cover: Unit{Column, 8},
+				Cells: []int{62, 71, 80},
+				Eliminations: []Candidate{
+					{8, 3}, {17, 3}, {26, 3}, {35, 3}, {44, 3}, {53, 3},
+				},
+			},
+			title: "Pointing Triple",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tc.grid.findPointing()
+			require.True(t, ok)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.title, got.Name())
+
+			_, ok = tc.grid.findBoxLine()
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestFindBoxLine(t *testing.T) {
+	// in row 1, 7 can only go in block 1
+	restrict := map[int][]int{}
+	for c := 3; c < 9; c++ {
+		restrict[c] = without(7)
+	}
+	g := candidateGrid(restrict)
+
+	_, ok := g.findPointing()
+	assert.False(t, ok)
+
+	got, ok := g.findBoxLine()
+	require.True(t, ok)
+	assert.Equal(t, LockedCandidates{
+		Digit: 7,
+		Base:  Unit{Row, 0},
+		Cover: Unit{Block, 0},
+		Cells: []int{0, 1, 2},
+		Eliminations: []Candidate{
+			{9, 7}, {10, 7}, {11, 7}, {18, 7}, {19, 7}, {20, 7},
+		},
+	}, got)
+	assert.Equal(t, "Box/Line Reduction: 7 in row 1 is locked to block 1 at r1c1,r1c2,r1c3 => "+
+		"r2c1<>7, r2c2<>7, r2c3<>7, r3c1<>7, r3c2<>7, r3c3<>7", got.String())
+}
+
+func TestNormalizeWithIntersections(t *testing.T) {
+	in := `
+		... ... .12
+		..8 .3. ...
+		... ... .4.
+
+		12. 5.. ...
+		... ..4 7..
+		.6. ... ...
+
+		5.7 ... 3..
+		... 62. ...
+		... 1.. ...`
+
+	g := NewGrid([]byte(in))
+	require.NoError(t, g.NormalizeWith(BasicPasses|IntersectionPasses))
+	assert.Equal(t, `346 795 812
+258 431 697
+971 862 543
+
+129 576 438
+835 214 769
+764 389 251
+
+517 948 326
+493 627 185
+682 153 974
+`, g.String())
+}
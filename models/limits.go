@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Limits bounds the searches made by the most expensive techniques.
+// The zero value of each field selects its default, so the zero Limits is
+// ready to use, and gives the same results however busy the machine is.
+type Limits struct {
+	// MaxChainLength is the largest number of candidates in a chain that
+	// the chain passes will look for. Longer chains find more
+	// eliminations, but are slower to find and harder to follow. The
+	// default is DefaultMaxChainLength.
+	MaxChainLength int
+	// ChainDepth is the longest chain of implications that a forcing chain
+	// will follow from each assumption. The default is DefaultChainDepth.
+	ChainDepth int
+	// NetDepth is the longest chain of implications that a forcing net
+	// will follow from each assumption. Since each step of a net may
+	// combine several earlier implications, nets grow much faster than
+	// chains, and are usually given a smaller depth. The default is
+	// DefaultNetDepth.
+	NetDepth int
+	// MaxALSSize is the largest number of squares in an almost locked set
+	// that the ALS passes will use. The number of sets, and the pairs of
+	// them to try, grow quickly with their size, while the larger sets are
+	// rarely needed. The default is DefaultMaxALSSize.
+	MaxALSSize int
+	// Timeout is the longest that a single forcing search may take before
+	// giving up. Zero means no limit. Setting a timeout makes the results
+	// depend on the speed of the machine.
+	Timeout time.Duration
+}
+
+// The default limits.
+const (
+	DefaultMaxChainLength = 12
+	DefaultChainDepth     = 20
+	DefaultNetDepth       = 8
+	DefaultMaxALSSize     = 6
+)
+
+// maxChainLength returns the length limit for the chain passes.
+func (l Limits) maxChainLength() int {
+	if l.MaxChainLength == 0 {
+		return DefaultMaxChainLength
+	}
+	return l.MaxChainLength
+}
+
+// maxALSSize returns the size limit for the ALS passes.
+func (l Limits) maxALSSize() int {
+	if l.MaxALSSize == 0 {
+		return DefaultMaxALSSize
+	}
+	return l.MaxALSSize
+}
+
+// chainDepth returns the depth for forcing chains.
+func (l Limits) chainDepth() int {
+	if l.ChainDepth == 0 {
+		return DefaultChainDepth
+	}
+	return l.ChainDepth
+}
+
+// netDepth returns the depth for forcing nets.
+func (l Limits) netDepth() int {
+	if l.NetDepth == 0 {
+		return DefaultNetDepth
+	}
+	return l.NetDepth
+}
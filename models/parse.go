@@ -0,0 +1,122 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+)
+
+// The kinds of ParseError, for use with errors.Is.
+var (
+	ErrTooFewCells      = errors.New("too few cells")
+	ErrTooManyCells     = errors.New("too many cells")
+	ErrInvalidCharacter = errors.New("invalid character")
+)
+
+// A ParseError describes why ParseGrid could not read a grid.
+type ParseError struct {
+	// Err is ErrTooFewCells, ErrTooManyCells or ErrInvalidCharacter.
+	Err error
+	// Offset is the byte offset of the problem within the input, counting
+	// from 0. For ErrTooFewCells, it is the length of the input.
+	Offset int
+	// Line and Column locate Offset within the input, counting from 1.
+	Line   int
+	Column int
+	// Char is the offending byte, for ErrInvalidCharacter and
+	// ErrTooManyCells.
+	Char byte
+	// Cells is the number of squares read before the problem.
+	Cells int
+}
+
+// Error implements the error interface, e.g.
+// "line 2, column 5 (offset 16): invalid character 'x'"
+func (e *ParseError) Error() string {
+	msg := "line " + strconv.Itoa(e.Line) +
+		", column " + strconv.Itoa(e.Column) +
+		" (offset " + strconv.Itoa(e.Offset) + "): " + e.Err.Error()
+	switch e.Err {
+	case ErrInvalidCharacter:
+		msg += " " + strconv.QuoteRune(rune(e.Char))
+	case ErrTooFewCells:
+		msg += ": found " + strconv.Itoa(e.Cells) + " of 81"
+	case ErrTooManyCells:
+		msg += ": expected 81"
+	}
+	return msg
+}
+
+// Unwrap returns the kind of error, so that errors.Is can test for it.
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// ParseGrid reads a sudoku grid from the given input, in the same format
+// as NewGrid, except that only whitespace may appear between the squares,
+// and there must be exactly 81 squares.
+// Returns a *ParseError describing the first problem with the input.
+func ParseGrid(in []byte) (Grid, error) {
+	return parseGrid(in, true)
+}
+
+// parseGrid reads a grid. If strict, it returns an error for any
+// character that is not a square or whitespace, and for the wrong number
+// of squares; otherwise it ignores them.
+func parseGrid(in []byte, strict bool) (Grid, error) {
+	g := Grid{
+		squares: new([81]Square),
+	}
+	i, line, col := 0, 1, 1
+	for offset, ch := range in {
+		var err error
+		switch ch {
+		case '0', '1', '2', '3', '4', '5', '6', '7', '8', '9', '.':
+			if i == 81 {
+				if !strict {
+					return g, nil
+				}
+				err = ErrTooManyCells
+				break
+			}
+			if ch == '.' {
+				ch = '0'
+			}
+			g.squares[i] = NewSquare(int(ch - '0'))
+			i++
+		case ' ', '\t', '\r', '\n':
+		default:
+			if strict {
+				err = ErrInvalidCharacter
+			}
+		}
+		if err != nil {
+			return Grid{}, &ParseError{
+				Err:    err,
+				Offset: offset,
+				Line:   line,
+				Column: col,
+				Char:   ch,
+				Cells:  i,
+			}
+		}
+		if ch == '\n' {
+			line, col = line+1, 1
+		} else {
+			col++
+		}
+	}
+
+	if i < 81 && strict {
+		return Grid{}, &ParseError{
+			Err:    ErrTooFewCells,
+			Offset: len(in),
+			Line:   line,
+			Column: col,
+			Cells:  i,
+		}
+	}
+	for ; i < 81; i++ {
+		g.squares[i] = any
+	}
+	return g, nil
+}
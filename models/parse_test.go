@@ -0,0 +1,107 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGrid(t *testing.T) {
+	for _, tc := range casesNewGrid {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseGrid([]byte(tc.in))
+			require.NoError(t, err)
+			require.Exactly(t, tc.want, *(got.squares))
+		})
+	}
+}
+
+func TestParseGridErrors(t *testing.T) {
+	row := "1.3 4.6 7.9\n"
+	testCases := []struct {
+		name string
+		in   string
+		want ParseError
+		msg  string
+	}{
+		{
+			name: "invalid character",
+			in:   row + "1.3 4x6 7.9\n",
+			want: ParseError{Err: ErrInvalidCharacter, Offset: 17, Line: 2, Column: 6, Char: 'x', Cells: 13},
+			msg:  "line 2, column 6 (offset 17): invalid character 'x'",
+		},
+		{
+			name: "too few cells",
+			in:   strings.Repeat(row, 8) + "123",
+			want: ParseError{Err: ErrTooFewCells, Offset: 99, Line: 9, Column: 4, Cells: 75},
+			msg:  "line 9, column 4 (offset 99): too few cells: found 75 of 81",
+		},
+		{
+			name: "empty",
+			in:   "",
+			want: ParseError{Err: ErrTooFewCells, Line: 1, Column: 1},
+			msg:  "line 1, column 1 (offset 0): too few cells: found 0 of 81",
+		},
+		{
+			name: "too many cells",
+			in:   strings.Repeat(".", 81) + " 5",
+			want: ParseError{Err: ErrTooManyCells, Offset: 82, Line: 1, Column: 83, Char: '5', Cells: 81},
+			msg:  "line 1, column 83 (offset 82): too many cells: expected 81",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParseGrid([]byte(tc.in))
+			require.Error(t, err)
+			assert.True(t, errors.Is(err, tc.want.Err))
+
+			var got *ParseError
+			require.True(t, errors.As(err, &got))
+			assert.Equal(t, tc.want, *got)
+			assert.Equal(t, tc.msg, err.Error())
+		})
+	}
+}
+
+func TestNewGridLenient(t *testing.T) {
+	testCases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "unknown characters are ignored",
+			in:   "1x2" + strings.Repeat(".", 79),
+			want: "12" + strings.Repeat(".", 79),
+		},
+		{
+			name: "missing squares are undefined",
+			in:   "123",
+			want: "123" + strings.Repeat(".", 78),
+		},
+		{
+			name: "extra squares are ignored",
+			in:   strings.Repeat("5", 90),
+			want: strings.Repeat("5", 81),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got := NewGrid([]byte(tc.in))
+			for ix := 0; ix < 81; ix++ {
+				assert.Equal(t, tc.want[ix], got.Get(ix).Display(), "square %d", ix)
+			}
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A SingleDigitKind identifies one of the single digit patterns.
+type SingleDigitKind int
+
+const (
+	// Skyscraper is two strong links in parallel rows (or columns), with
+	// one end of each in the same column (or row).
+	Skyscraper SingleDigitKind = iota
+	// TwoStringKite is a strong link in a row and another in a column,
+	// with one end of each in the same block.
+	TwoStringKite
+	// EmptyRectangle is a block in which the digit is confined to one row
+	// and one column, together with a strong link that meets one of them.
+	EmptyRectangle
+	// TurbotFish is any other pair of strong links, where one end of the
+	// first sees one end of the second.
+	TurbotFish
+)
+
+// String implements the fmt.Stringer interface
+func (k SingleDigitKind) String() string {
+	switch k {
+	case Skyscraper:
+		return "Skyscraper"
+	case TwoStringKite:
+		return "2-String Kite"
+	case EmptyRectangle:
+		return "Empty Rectangle"
+	case TurbotFish:
+		return "Turbot Fish"
+	default:
+		return "Single Digit Pattern"
+	}
+}
+
+// A SingleDigitPattern describes one of the chain-like patterns that work
+// on the candidates for a single digit.
+//
+// For a Skyscraper, 2-String Kite or Turbot Fish, Cells holds the four ends
+// of the two strong links in chain order, a=b-c=d, and Units holds the
+// units of the two strong links. Either a or d must hold the digit.
+//
+// For an Empty Rectangle, Cells holds the candidates in the block followed
+// by the two ends of the strong link, and Units holds the block and the
+// unit of the strong link.
+type SingleDigitPattern struct {
+	Kind         SingleDigitKind
+	Digit        int
+	Cells        []int
+	Units        []Unit
+	Eliminations []Candidate
+}
+
+// Name returns the conventional name of the pattern, e.g. "Skyscraper"
+func (p SingleDigitPattern) Name() string {
+	return p.Kind.String()
+}
+
+// String implements the fmt.Stringer interface
+func (p SingleDigitPattern) String() string {
+	var b strings.Builder
+	b.WriteString(p.Name())
+	b.WriteString(": ")
+	b.WriteString(strconv.Itoa(p.Digit))
+	b.WriteString(" in ")
+	b.WriteString(formatUnits(p.Units))
+	b.WriteString(" at ")
+	b.WriteString(formatCells(p.Cells))
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(p.Eliminations))
+	return b.String()
+}
+
+// A strongLink is a unit in which a digit can only go in two squares.
+type strongLink struct {
+	unit int
+	a, b int
+}
+
+// strongLinks returns every strong link for digit d
+func (g Grid) strongLinks(d int) []strongLink {
+	var links []strongLink
+	for u := 0; u < 27; u++ {
+		if cells := g.positions(u, d); len(cells) == 2 {
+			links = append(links, strongLink{u, cells[0], cells[1]})
+		}
+	}
+	return links
+}
+
+// findSkyscraper looks for a Skyscraper that allows at least one
+// candidate to be eliminated.
+func (g Grid) findSkyscraper() (SingleDigitPattern, bool) {
+	return g.findLinkedPair(Skyscraper)
+}
+
+// findTwoStringKite looks for a 2-String Kite that allows at least one
+// candidate to be eliminated.
+func (g Grid) findTwoStringKite() (SingleDigitPattern, bool) {
+	return g.findLinkedPair(TwoStringKite)
+}
+
+// findTurbotFish looks for a Turbot Fish that is neither a Skyscraper nor
+// a 2-String Kite, and allows at least one candidate to be eliminated.
+func (g Grid) findTurbotFish() (SingleDigitPattern, bool) {
+	return g.findLinkedPair(TurbotFish)
+}
+
+// findLinkedPair looks for two strong links a=b and c=d on the same digit,
+// where b sees c, that form a pattern of the given kind. Since either a or
+// d must hold the digit, it is eliminated from every square seeing both.
+func (g Grid) findLinkedPair(kind SingleDigitKind) (SingleDigitPattern, bool) {
+	for d := 1; d <= 9; d++ {
+		links := g.strongLinks(d)
+		for i, l1 := range links {
+			for _, l2 := range links[i+1:] {
+				for _, x := range [2][2]int{{l1.a, l1.b}, {l1.b, l1.a}} {
+					for _, y := range [2][2]int{{l2.a, l2.b}, {l2.b, l2.a}} {
+						chain := []int{x[0], x[1], y[0], y[1]}
+						if !distinct(chain) || !Sees(x[1], y[0]) {
+							continue
+						}
+						if classifyLinkedPair(l1.unit, l2.unit, x[1], y[0]) != kind {
+							continue
+						}
+						elims := g.eliminationsSeeing(d, []int{x[0], y[1]})
+						if len(elims) == 0 {
+							continue
+						}
+						return SingleDigitPattern{
+							Kind:         kind,
+							Digit:        d,
+							Cells:        chain,
+							Units:        []Unit{unitByID(l1.unit), unitByID(l2.unit)},
+							Eliminations: elims,
+						}, true
+					}
+				}
+			}
+		}
+	}
+	return SingleDigitPattern{}, false
+}
+
+// classifyLinkedPair names the pattern made by strong links in units u1
+// and u2, which are joined by the squares b and c.
+func classifyLinkedPair(u1, u2, b, c int) SingleDigitKind {
+	k1, k2 := UnitKind(u1/9), UnitKind(u2/9)
+	switch {
+	case k1 == Row && k2 == Row && b%9 == c%9:
+		return Skyscraper
+	case k1 == Column && k2 == Column && b/9 == c/9:
+		return Skyscraper
+	case k1 != Block && k2 != Block && k1 != k2 && cellUnits[b][2] == cellUnits[c][2]:
+		return TwoStringKite
+	default:
+		return TurbotFish
+	}
+}
+
+// findEmptyRectangle looks for an Empty Rectangle that allows at least one
+// candidate to be eliminated.
+func (g Grid) findEmptyRectangle() (SingleDigitPattern, bool) {
+	for d := 1; d <= 9; d++ {
+		links := g.strongLinks(d)
+		for b := 18; b < 27; b++ {
+			box := g.positions(b, d)
+			if len(box) < 2 {
+				continue
+			}
+			first := unitCells[b][0]
+			for r := first / 9; r < first/9+3; r++ {
+				for c := first % 9; c < first%9+3; c++ {
+					if !isEmptyRectangle(box, r, c) {
+						continue
+					}
+					if p, ok := g.emptyRectangle(d, b, r, c, box, links); ok {
+						return p, true
+					}
+				}
+			}
+		}
+	}
+	return SingleDigitPattern{}, false
+}
+
+// isEmptyRectangle reports whether all of the given cells lie in row r or
+// column c, but not all in the same one.
+func isEmptyRectangle(cells []int, r, c int) bool {
+	inRow, inCol := false, false
+	for _, n := range cells {
+		switch {
+		case n/9 == r && n%9 == c:
+		case n/9 == r:
+			inRow = true
+		case n%9 == c:
+			inCol = true
+		default:
+			return false
+		}
+	}
+	return inRow && inCol
+}
+
+// emptyRectangle looks for a strong link that combines with the empty
+// rectangle in block b, on row r and column c, to eliminate a candidate.
+//
+// If a strong link in another row has one end in column c, then either
+// that end holds d, which forces d into row r within the block, or the
+// other end does. Both remove d from the square in row r below (or above)
+// the other end. Strong links in columns work the same way.
+func (g Grid) emptyRectangle(d, b, r, c int, box []int, links []strongLink) (SingleDigitPattern, bool) {
+	for _, l := range links {
+		kind := UnitKind(l.unit / 9)
+		if kind == Block {
+			continue
+		}
+		for _, ends := range [2][2]int{{l.a, l.b}, {l.b, l.a}} {
+			meet, other := ends[0], ends[1]
+			if cellUnits[meet][2] == b || cellUnits[other][2] == b {
+				continue
+			}
+			var target int
+			if kind == Row {
+				if meet%9 != c {
+					continue
+				}
+				target = r*9 + other%9
+			} else {
+				if meet/9 != r {
+					continue
+				}
+				target = (other/9)*9 + c
+			}
+			sq := g.squares[target]
+			if cellUnits[target][2] == b || !sq.Has(d) || sq.IsDefined() {
+				continue
+			}
+			return SingleDigitPattern{
+				Kind:         EmptyRectangle,
+				Digit:        d,
+				Cells:        append(append([]int(nil), box...), meet, other),
+				Units:        []Unit{unitByID(b), unitByID(l.unit)},
+				Eliminations: []Candidate{{target, d}},
+			}, true
+		}
+	}
+	return SingleDigitPattern{}, false
+}
+
+// distinct reports whether no two of the given cells are the same
+func distinct(cells []int) bool {
+	for i, a := range cells {
+		if containsInt(cells[i+1:], a) {
+			return false
+		}
+	}
+	return true
+}
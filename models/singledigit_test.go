@@ -0,0 +1,120 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// excluding removes digit d from each of the given squares
+func excluding(restrict map[int][]int, d int, cells ...int) map[int][]int {
+	for _, c := range cells {
+		restrict[c] = without(d)
+	}
+	return restrict
+}
+
+func TestFindSingleDigitPatterns(t *testing.T) {
+	tt := []struct {
+		name string
+		grid Grid
+		find func(Grid) (SingleDigitPattern, bool)
+		want SingleDigitPattern
+		desc string
+	}{
+		{
+			name: "skyscraper",
+			grid: candidateGrid(
+				onlyIn(onlyIn(map[int][]int{}, 6, []int{0}, []int{0, 4}), 6, []int{4}, []int{0, 5})),
+			find: Grid.findSkyscraper,
+			want: SingleDigitPattern{
+				Kind:         Skyscraper,
+				Digit:        6,
+				Cells:        []int{4, 0, 36, 41},
+				Units:        []Unit{{Row, 0}, {Row, 4}},
+				Eliminations: []Candidate{{14, 6}, {23, 6}, {31, 6}, {49, 6}},
+			},
+			desc: "Skyscraper: 6 in row 1, row 5 at r1c5,r1c1,r5c1,r5c6 => " +
+				"r2c6<>6, r3c6<>6, r4c5<>6, r6c5<>6",
+		},
+		{
+			name: "2-string kite",
+			grid: candidateGrid(transpose(
+				onlyIn(transpose(onlyIn(map[int][]int{}, 2, []int{0}, []int{1, 7})), 2, []int{0}, []int{2, 7}))),
+			find: Grid.findTwoStringKite,
+			want: SingleDigitPattern{
+				Kind:         TwoStringKite,
+				Digit:        2,
+				Cells:        []int{7, 1, 18, 63},
+				Units:        []Unit{{Row, 0}, {Column, 0}},
+				Eliminations: []Candidate{{70, 2}},
+			},
+			desc: "2-String Kite: 2 in row 1, column 1 at r1c8,r1c2,r3c1,r8c1 => r8c8<>2",
+		},
+		{
+			name: "turbot fish",
+			grid: candidateGrid(onlyIn(
+				excluding(map[int][]int{}, 3, 1, 2, 9, 10, 11, 18, 19),
+				3, []int{4}, []int{2, 8})),
+			find: Grid.findTurbotFish,
+			want: SingleDigitPattern{
+				Kind:         TurbotFish,
+				Digit:        3,
+				Cells:        []int{44, 38, 20, 0},
+				Units:        []Unit{{Row, 4}, {Block, 0}},
+				Eliminations: []Candidate{{8, 3}},
+			},
+			desc: "Turbot Fish: 3 in row 5, block 1 at r5c9,r5c3,r3c3,r1c1 => r1c9<>3",
+		},
+		{
+			name: "empty rectangle",
+			grid: candidateGrid(onlyIn(
+				excluding(map[int][]int{}, 5, 0, 2, 10, 11, 19, 20),
+				5, []int{5}, []int{0, 7})),
+			find: Grid.findEmptyRectangle,
+			want: SingleDigitPattern{
+				Kind:         EmptyRectangle,
+				Digit:        5,
+				Cells:        []int{1, 9, 18, 45, 52},
+				Units:        []Unit{{Block, 0}, {Row, 5}},
+				Eliminations: []Candidate{{7, 5}},
+			},
+			desc: "Empty Rectangle: 5 in block 1, row 6 at r1c2,r2c1,r3c1,r6c1,r6c8 => r1c8<>5",
+		},
+	}
+
+	finders := []func(Grid) (SingleDigitPattern, bool){
+		Grid.findSkyscraper, Grid.findTwoStringKite, Grid.findTurbotFish,
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tc.find(tc.grid)
+			require.True(t, ok)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.desc, got.String())
+
+			for _, find := range finders {
+				if p, ok := find(tc.grid); ok {
+					assert.Equal(t, tc.want.Kind, p.Kind, "found the wrong kind of pattern")
+				}
+			}
+		})
+	}
+}
+
+func TestSingleDigitStep(t *testing.T) {
+	g := candidateGrid(
+		onlyIn(onlyIn(map[int][]int{}, 6, []int{0}, []int{0, 4}), 6, []int{4}, []int{0, 5}))
+	step, ok := BuiltinTechniques(PassSkyscraper)[0].Apply(&g)
+	require.True(t, ok)
+
+	a := assert.New(t)
+	a.Equal("Skyscraper", step.Technique)
+	a.Equal(6, step.Digit)
+	a.Equal([]int{4, 0, 36, 41}, step.Cells, "the ends of the strong links, in chain order")
+	a.Equal([]Unit{{Row, 0}, {Row, 4}}, step.Units, "the units of the strong links")
+}
@@ -1,5 +1,7 @@
 package models
 
+import "math/bits"
+
 // A Square represents the set of possible values for a given sudoku square
 type Square uint16
 
@@ -63,3 +65,22 @@ func (sq Square) Values() []int {
 func (sq Square) IsDefined() bool {
 	return sq != 0 && sq&(sq-1) == 0
 }
+
+// Count returns the number of potential values that this square could hold
+func (sq Square) Count() int {
+	return bits.OnesCount16(uint16(sq))
+}
+
+// Has reports whether this square could hold the value k
+func (sq Square) Has(k int) bool {
+	return k >= 1 && k <= 9 && sq&squareEnum[k] != 0
+}
+
+// Value returns the value of a defined square, or 0 if the square
+// is not defined.
+func (sq Square) Value() int {
+	if !sq.IsDefined() {
+		return 0
+	}
+	return bits.TrailingZeros16(uint16(sq)) + 1
+}
@@ -108,3 +108,33 @@ func TestSquareIsSingle(t *testing.T) {
 		})
 	}
 }
+
+func TestSquareCountHasValue(t *testing.T) {
+	tt := []struct {
+		name  string
+		in    Square
+		count int
+		has   []int
+		value int
+	}{
+		{"none", none, 0, nil, 0},
+		{"five", five, 1, []int{5}, 5},
+		{"nine", nine, 1, []int{9}, 9},
+		{"two + seven", two | seven, 2, []int{2, 7}, 0},
+		{"any", any, 9, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, 0},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			r := require.New(t)
+
+			r.Equal(tc.count, tc.in.Count())
+			r.Equal(tc.value, tc.in.Value())
+			for k := 0; k <= 10; k++ {
+				r.Equalf(containsInt(tc.has, k), tc.in.Has(k), "Has(%d)", k)
+			}
+		})
+	}
+}
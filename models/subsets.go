@@ -0,0 +1,213 @@
+package models
+
+import (
+	"math/bits"
+	"strings"
+)
+
+// A Subset is a group of n squares within a unit that between them are
+// restricted to exactly n values.
+//
+// In a naked subset the squares cannot hold any value other than Digits,
+// so no other square in the unit can hold any of Digits.
+// In a hidden subset none of Digits can go anywhere else in the unit,
+// so the squares cannot hold any value other than Digits.
+type Subset struct {
+	Naked        bool
+	Unit         Unit
+	Cells        []int
+	Digits       Square
+	Eliminations []Candidate
+}
+
+var subsetSizes = [...]string{2: "Pair", 3: "Triple", 4: "Quad"}
+
+// Name returns the conventional name of the subset, e.g. "Hidden Triple"
+func (s Subset) Name() string {
+	if s.Naked {
+		return "Naked " + subsetSizes[len(s.Cells)]
+	}
+	return "Hidden " + subsetSizes[len(s.Cells)]
+}
+
+// String implements the fmt.Stringer interface
+func (s Subset) String() string {
+	var b strings.Builder
+	b.WriteString(s.Name())
+	b.WriteString(" ")
+	b.WriteString(formatDigits(s.Digits))
+	b.WriteString(" in ")
+	b.WriteString(s.Unit.String())
+	b.WriteString(" at ")
+	b.WriteString(formatCells(s.Cells))
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(s.Eliminations))
+	return b.String()
+}
+
+// findNakedSubset looks for a naked subset of the given size that allows
+// at least one candidate to be eliminated.
+func (g Grid) findNakedSubset(size int) (Subset, bool) {
+	for u := 0; u < 27; u++ {
+		open := g.openCells(u)
+		if len(open) <= size {
+			continue
+		}
+
+		var found Subset
+		forEachCombination(open, size, func(cells []int) bool {
+			digits := none
+			for _, c := range cells {
+				digits |= g.squares[c]
+			}
+			if digits.Count() != size {
+				return true
+			}
+			var elims []Candidate
+			for _, c := range open {
+				if containsInt(cells, c) {
+					continue
+				}
+				for _, d := range (g.squares[c] & digits).Values() {
+					elims = append(elims, Candidate{c, d})
+				}
+			}
+			if len(elims) == 0 {
+				return true
+			}
+			found = Subset{
+				Naked:        true,
+				Unit:         unitByID(u),
+				Cells:        append([]int(nil), cells...),
+				Digits:       digits,
+				Eliminations: elims,
+			}
+			return false
+		})
+		if found.Cells != nil {
+			return found, true
+		}
+	}
+	return Subset{}, false
+}
+
+// findHiddenSubset looks for a hidden subset of the given size that allows
+// at least one candidate to be eliminated.
+func (g Grid) findHiddenSubset(size int) (Subset, bool) {
+	for u := 0; u < 27; u++ {
+		open := g.openCells(u)
+		if len(open) <= size {
+			continue
+		}
+
+		// where[d] is the set of positions within open that could hold d
+		var where [10]uint16
+		digits := make([]int, 0, 9)
+		for d := 1; d <= 9; d++ {
+			for i, c := range open {
+				if g.squares[c].Has(d) {
+					where[d] |= 1 << i
+				}
+			}
+			if where[d] != 0 {
+				digits = append(digits, d)
+			}
+		}
+		if len(digits) <= size {
+			continue
+		}
+
+		var found Subset
+		forEachCombination(digits, size, func(ds []int) bool {
+			var pos uint16
+			set := none
+			for _, d := range ds {
+				pos |= where[d]
+				set |= squareEnum[d]
+			}
+			if bits.OnesCount16(pos) != size {
+				return true
+			}
+			var cells []int
+			var elims []Candidate
+			for i, c := range open {
+				if pos&(1<<i) == 0 {
+					continue
+				}
+				cells = append(cells, c)
+				for _, d := range (g.squares[c] &^ set).Values() {
+					elims = append(elims, Candidate{c, d})
+				}
+			}
+			if len(elims) == 0 {
+				return true
+			}
+			found = Subset{
+				Unit:         unitByID(u),
+				Cells:        cells,
+				Digits:       set,
+				Eliminations: elims,
+			}
+			return false
+		})
+		if found.Cells != nil {
+			return found, true
+		}
+	}
+	return Subset{}, false
+}
+
+// openCells returns the squares in the unit with the given id
+// that are not yet defined.
+func (g Grid) openCells(u int) []int {
+	open := make([]int, 0, 9)
+	for _, c := range unitCells[u] {
+		if !g.squares[c].IsDefined() {
+			open = append(open, c)
+		}
+	}
+	return open
+}
+
+// forEachCombination calls fn with each subset of k items, in lexicographic
+// order, until fn returns false. The slice passed to fn is reused between
+// calls. Returns false if fn stopped the iteration.
+func forEachCombination(items []int, k int, fn func([]int) bool) bool {
+	if k > len(items) || k <= 0 {
+		return true
+	}
+	ix := make([]int, k)
+	combo := make([]int, k)
+	for i := range ix {
+		ix[i] = i
+	}
+	for {
+		for i, j := range ix {
+			combo[i] = items[j]
+		}
+		if !fn(combo) {
+			return false
+		}
+		// advance to the next combination
+		i := k - 1
+		for i >= 0 && ix[i] == len(items)-k+i {
+			i--
+		}
+		if i < 0 {
+			return true
+		}
+		ix[i]++
+		for j := i + 1; j < k; j++ {
+			ix[j] = ix[j-1] + 1
+		}
+	}
+}
+
+func containsInt(xs []int, x int) bool {
+	for _, y := range xs {
+		if y == x {
+			return true
+		}
+	}
+	return false
+}
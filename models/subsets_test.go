@@ -0,0 +1,179 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// candidateGrid returns a grid where every square could be any value,
+// except for the squares in restrict, which are limited to the given values.
+func candidateGrid(restrict map[int][]int) Grid {
+	g := NewGrid([]byte(strings81('.')))
+	for n, vals := range restrict {
+		g.squares[n] = none
+		for _, v := range vals {
+			g.squares[n] |= squareEnum[v]
+		}
+	}
+	return g
+}
+
+func strings81(ch byte) string {
+	b := make([]byte, 81)
+	for i := range b {
+		b[i] = ch
+	}
+	return string(b)
+}
+
+func TestFindNakedSubset(t *testing.T) {
+	tt := []struct {
+		name     string
+		size     int
+		grid     Grid
+		unit     Unit
+		cells    []int
+		digits   Square
+		numElims int
+	}{
+		{
+			name:     "pair in a row",
+			size:     2,
+			grid:     candidateGrid(map[int][]int{3: {1, 2}, 7: {1, 2}}),
+			unit:     Unit{Row, 0},
+			cells:    []int{3, 7},
+			digits:   one | two,
+			numElims: 14,
+		},
+		{
+			name: "triple in a column",
+			size: 3,
+			grid: candidateGrid(map[int][]int{
+				4: {4, 5}, 31: {5, 6}, 76: {4, 6},
+			}),
+			unit:     Unit{Column, 4},
+			cells:    []int{4, 31, 76},
+			digits:   four | five | six,
+			numElims: 18,
+		},
+		{
+			name: "quad in a block",
+			size: 4,
+			grid: candidateGrid(map[int][]int{
+				60: {1, 9}, 61: {1, 2}, 70: {2, 3}, 80: {3, 9},
+			}),
+			unit:     Unit{Block, 8},
+			cells:    []int{60, 61, 70, 80},
+			digits:   one | two | three | nine,
+			numElims: 20,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tc.grid.findNakedSubset(tc.size)
+			require.True(t, ok)
+			assert.True(t, got.Naked)
+			assert.Equal(t, tc.unit, got.Unit)
+			assert.Equal(t, tc.cells, got.Cells)
+			assert.Equal(t, tc.digits, got.Digits)
+			assert.Len(t, got.Eliminations, tc.numElims)
+			for _, c := range got.Eliminations {
+				assert.NotContains(t, tc.cells, c.Cell)
+				assert.True(t, tc.digits.Has(c.Digit))
+			}
+		})
+	}
+}
+
+func TestFindHiddenSubset(t *testing.T) {
+	// 1 and 2 can only go in r1c1 and r1c5 within the first row
+	restrict := map[int][]int{}
+	for c := 1; c < 9; c++ {
+		if c != 4 {
+			restrict[c] = []int{3, 4, 5, 6, 7, 8, 9}
+		}
+	}
+	g := candidateGrid(restrict)
+
+	got, ok := g.findHiddenSubset(2)
+	require.True(t, ok)
+	assert.False(t, got.Naked)
+	assert.Equal(t, "Hidden Pair", got.Name())
+	assert.Equal(t, Unit{Row, 0}, got.Unit)
+	assert.Equal(t, []int{0, 4}, got.Cells)
+	assert.Equal(t, one|two, got.Digits)
+	assert.Len(t, got.Eliminations, 14)
+
+	require.NoError(t, g.Apply(Step{Eliminations: got.Eliminations}))
+	assert.Equal(t, one|two, g.squares[0])
+	assert.Equal(t, one|two, g.squares[4])
+
+	_, ok = g.findHiddenSubset(2)
+	assert.False(t, ok, "the subset should not be found again")
+}
+
+func TestSubsetString(t *testing.T) {
+	g := candidateGrid(map[int][]int{0: {1, 2}, 1: {1, 2}, 2: {1, 2, 3}})
+	got, ok := g.findNakedSubset(2)
+	require.True(t, ok)
+	assert.Equal(t, "Naked Pair", got.Name())
+	assert.Regexp(t, `^Naked Pair \{1,2\} in row 1 at r1c1,r1c2 => r1c3<>1, r1c3<>2, `,
+		got.String())
+}
+
+func TestNormalizeWithSubsets(t *testing.T) {
+	in := `
+		... ... .12
+		..8 .3. ...
+		... ... .4.
+
+		12. 5.. ...
+		... ..4 7..
+		.6. ... ...
+
+		5.7 ... 3..
+		... 62. ...
+		... 1.. ...`
+	want := `346 795 812
+258 431 697
+971 862 543
+
+129 576 438
+835 214 769
+764 389 251
+
+517 948 326
+493 627 185
+682 153 974
+`
+
+	basic := NewGrid([]byte(in))
+	require.NoError(t, basic.Normalize())
+	assert.NotEqual(t, want, basic.String(), "basic passes should get stuck")
+
+	subsets := NewGrid([]byte(in))
+	require.NoError(t, subsets.NormalizeWith(BasicPasses|SubsetPasses))
+	assert.Equal(t, want, subsets.String())
+}
+
+func TestForEachCombination(t *testing.T) {
+	var got [][]int
+	forEachCombination([]int{1, 2, 3, 4}, 2, func(c []int) bool {
+		got = append(got, append([]int(nil), c...))
+		return true
+	})
+	assert.Equal(t, [][]int{{1, 2}, {1, 3}, {1, 4}, {2, 3}, {2, 4}, {3, 4}}, got)
+
+	n := 0
+	done := forEachCombination([]int{1, 2, 3, 4}, 3, func([]int) bool {
+		n++
+		return n < 2
+	})
+	assert.False(t, done)
+	assert.Equal(t, 2, n)
+}
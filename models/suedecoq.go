@@ -0,0 +1,183 @@
+package models
+
+import "strings"
+
+// A SueDeCoq describes a locked set spread over the intersection of a
+// block and a line (a row or column).
+//
+// Cells holds two or three squares in the intersection, which between them
+// hold at least two more values than they have squares. LineCells holds
+// squares elsewhere in the line, and BlockCells squares elsewhere in the
+// block, with no values in common between the two. Together, the squares
+// hold exactly as many values as there are squares, so each value appears
+// exactly once among them.
+//
+// The values of LineCells, and the values of Cells that are not in
+// BlockCells, are confined to the line, so can be removed from the rest of
+// it; and likewise for the block.
+type SueDeCoq struct {
+	Line         Unit
+	Block        Unit
+	Cells        []int
+	LineCells    []int
+	BlockCells   []int
+	Eliminations []Candidate
+}
+
+// Name returns the conventional name of the pattern, "Sue de Coq"
+func (s SueDeCoq) Name() string {
+	return "Sue de Coq"
+}
+
+// String implements the fmt.Stringer interface
+func (s SueDeCoq) String() string {
+	var b strings.Builder
+	b.WriteString(s.Name())
+	b.WriteString(": ")
+	b.WriteString(formatCells(s.Cells))
+	b.WriteString(" in ")
+	b.WriteString(s.Line.String())
+	b.WriteString(" and ")
+	b.WriteString(s.Block.String())
+	b.WriteString(" with ")
+	b.WriteString(formatCells(s.LineCells))
+	b.WriteString(" and ")
+	b.WriteString(formatCells(s.BlockCells))
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(s.Eliminations))
+	return b.String()
+}
+
+// findSueDeCoq looks for a Sue de Coq that allows at least one candidate
+// to be eliminated.
+func (g Grid) findSueDeCoq() (SueDeCoq, bool) {
+	for b := 18; b < 27; b++ {
+		for line := 0; line < 18; line++ {
+			var inter, lineRest, blockRest []int
+			for _, c := range g.openCells(line) {
+				if cellUnits[c][2] == b {
+					inter = append(inter, c)
+				} else {
+					lineRest = append(lineRest, c)
+				}
+			}
+			if len(inter) < 2 {
+				continue
+			}
+			for _, c := range g.openCells(b) {
+				if !containsInt(unitCells[line][:], c) {
+					blockRest = append(blockRest, c)
+				}
+			}
+
+			var found SueDeCoq
+			for size := 2; size <= len(inter) && found.Cells == nil; size++ {
+				forEachCombination(inter, size, func(cells []int) bool {
+					found, _ = g.sueDeCoq(line, b, cells, lineRest, blockRest)
+					return found.Cells == nil
+				})
+			}
+			if found.Cells != nil {
+				return found, true
+			}
+		}
+	}
+	return SueDeCoq{}, false
+}
+
+// sueDeCoq looks for squares in lineRest and blockRest that combine with
+// the given intersection squares to make a Sue de Coq.
+func (g Grid) sueDeCoq(line, block int, cells, lineRest, blockRest []int) (SueDeCoq, bool) {
+	v := g.union(cells)
+	if v.Count() < len(cells)+2 {
+		return SueDeCoq{}, false
+	}
+	// only squares sharing a value with the intersection can help
+	lineRest = g.cellsWith(lineRest, v)
+	blockRest = g.cellsWith(blockRest, v)
+
+	var found SueDeCoq
+	for nd := 1; nd <= len(lineRest) && found.Cells == nil; nd++ {
+		forEachCombination(lineRest, nd, func(d []int) bool {
+			vd := g.union(d)
+			for ne := 1; ne <= len(blockRest); ne++ {
+				forEachCombination(blockRest, ne, func(e []int) bool {
+					ve := g.union(e)
+					if vd&ve != none || (v|vd|ve).Count() != len(cells)+nd+ne {
+						return true
+					}
+					found, _ = g.sueDeCoqEliminations(line, block, cells, d, e, vd|(v&^ve), ve|(v&^vd))
+					return found.Cells == nil
+				})
+				if found.Cells != nil {
+					return false
+				}
+			}
+			return true
+		})
+	}
+	return found, found.Cells != nil
+}
+
+// sueDeCoqEliminations builds the pattern, removing lineDigits from the
+// rest of the line and blockDigits from the rest of the block.
+// Returns false if there is nothing to eliminate.
+func (g Grid) sueDeCoqEliminations(line, block int, cells, d, e []int, lineDigits, blockDigits Square) (SueDeCoq, bool) {
+	var elims []Candidate
+	check := func(c int) {
+		sq := g.squares[c]
+		if sq.IsDefined() || containsInt(cells, c) || containsInt(d, c) || containsInt(e, c) {
+			return
+		}
+		mask := none
+		if cellUnits[c][line/9] == line {
+			mask |= lineDigits
+		}
+		if cellUnits[c][2] == block {
+			mask |= blockDigits
+		}
+		for _, x := range (sq & mask).Values() {
+			elims = append(elims, Candidate{c, x})
+		}
+	}
+	for _, c := range unitCells[line] {
+		check(c)
+	}
+	for _, c := range unitCells[block] {
+		if cellUnits[c][line/9] != line {
+			check(c)
+		}
+	}
+	if len(elims) == 0 {
+		return SueDeCoq{}, false
+	}
+	return SueDeCoq{
+		Line:         unitByID(line),
+		Block:        unitByID(block),
+		Cells:        append([]int(nil), cells...),
+		LineCells:    append([]int(nil), d...),
+		BlockCells:   append([]int(nil), e...),
+		Eliminations: elims,
+	}, true
+}
+
+// union returns every value that any of the given squares could hold.
+func (g Grid) union(cells []int) Square {
+	u := none
+	for _, c := range cells {
+		u |= g.squares[c]
+	}
+	return u
+}
+
+// cellsWith returns the given cells that could hold any of the values
+// in mask.
+func (g Grid) cellsWith(cells []int, mask Square) []int {
+	var out []int
+	for _, c := range cells {
+		if g.squares[c]&mask != none {
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindSueDeCoq(t *testing.T) {
+	g := candidateGrid(map[int][]int{
+		0: {1, 2, 3, 4}, 1: {1, 2, 3, 4}, // the intersection of row 1 and block 1
+		4: {1, 2}, // row 1
+		9: {3, 4}, // block 1
+	})
+
+	got, ok := g.findSueDeCoq()
+	require.True(t, ok)
+
+	var elims []Candidate
+	elims = append(elims, Candidate{2, 1}, Candidate{2, 2}, Candidate{2, 3}, Candidate{2, 4})
+	for _, c := range []int{3, 5, 6, 7, 8} {
+		elims = append(elims, Candidate{c, 1}, Candidate{c, 2})
+	}
+	for _, c := range []int{10, 11, 18, 19, 20} {
+		elims = append(elims, Candidate{c, 3}, Candidate{c, 4})
+	}
+	assert.Equal(t, SueDeCoq{
+		Line:         Unit{Row, 0},
+		Block:        Unit{Block, 0},
+		Cells:        []int{0, 1},
+		LineCells:    []int{4},
+		BlockCells:   []int{9},
+		Eliminations: elims,
+	}, got)
+	assert.Equal(t, "Sue de Coq: r1c1,r1c2 in row 1 and block 1 with r1c5 and r2c1 => "+
+		"r1c3<>1, r1c3<>2, r1c3<>3, r1c3<>4, r1c4<>1, r1c4<>2, r1c6<>1, r1c6<>2, "+
+		"r1c7<>1, r1c7<>2, r1c8<>1, r1c8<>2, r1c9<>1, r1c9<>2, "+
+		"r2c2<>3, r2c2<>4, r2c3<>3, r2c3<>4, r3c1<>3, r3c1<>4, "+
+		"r3c2<>3, r3c2<>4, r3c3<>3, r3c3<>4", got.String())
+}
+
+func TestFindSueDeCoqNone(t *testing.T) {
+	// the line and block squares share a value, so are not disjoint
+	g := candidateGrid(map[int][]int{
+		0: {1, 2, 3, 4}, 1: {1, 2, 3, 4},
+		4: {1, 2},
+		9: {2, 3, 4},
+	})
+	_, ok := g.findSueDeCoq()
+	assert.False(t, ok)
+}
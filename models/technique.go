@@ -0,0 +1,464 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"sync"
+)
+
+// A Step is a single deduction made by a Technique: the values that it
+// places, and the candidates that it eliminates. Placing a value also
+// removes it from the peers of its square, so those eliminations are not
+// listed separately.
+type Step struct {
+	// Technique is the name of the pattern that was found,
+	// e.g. "Pointing Pair".
+	Technique string `json:"technique"`
+	// Difficulty is how hard the step is to find, on the Sudoku Explainer
+	// scale.
+	Difficulty   float64     `json:"difficulty"`
+	Placements   []Candidate `json:"placements,omitempty"`
+	Eliminations []Candidate `json:"eliminations,omitempty"`
+	// Cells and Units are the squares and units that make up the pattern.
+	// For a single digit pattern, they are ordered as described on
+	// SingleDigitPattern.
+	Cells []int  `json:"cells,omitempty"`
+	Units []Unit `json:"units,omitempty"`
+	// Digit is the value that a single digit pattern, such as a fish, is
+	// built on, or the value of the strong link of a W-Wing.
+	Digit int `json:"digit,omitempty"`
+	// Base and Cover are the base and cover units of a fish, and Fins are
+	// its fins. The fins are not listed in Cells.
+	Base  []Unit `json:"base,omitempty"`
+	Cover []Unit `json:"cover,omitempty"`
+	Fins  []int  `json:"fins,omitempty"`
+	// Pivot and Pincers are the squares of a wing. The pivot of a W-Wing
+	// is the two ends of its strong link.
+	Pivot   []int `json:"pivot,omitempty"`
+	Pincers []int `json:"pincers,omitempty"`
+	// Colors holds the squares of each color of a coloring: the two
+	// colors of the first cluster, then those of the second, if any, as
+	// described on Coloring.
+	Colors [][]int `json:"colors,omitempty"`
+	// Proof holds a branch for each assumption of a forcing chain or net,
+	// showing how it leads to the eliminations.
+	Proof []Branch `json:"proof,omitempty"`
+	// Description explains the step,
+	// e.g. "Hidden Single: r1c1=5 in block 1".
+	Description string `json:"description"`
+}
+
+// String implements the fmt.Stringer interface
+func (s Step) String() string {
+	return s.Description
+}
+
+// A Technique is a rule for refining a grid, one step at a time.
+// The built-in techniques are returned by BuiltinTechniques, and other
+// packages may add their own to a Registry.
+type Technique interface {
+	// Name returns the name of the technique, e.g. "X-Wing".
+	Name() string
+	// Difficulty returns how hard the technique is to apply, on the Sudoku
+	// Explainer scale. Registries are ordered by difficulty.
+	Difficulty() float64
+	// Apply looks for a single step, and makes it on the given grid.
+	// Returns false, leaving the grid unchanged, if there is nothing to do.
+	// A step that leaves the grid invalid is still applied; Propagate will
+	// report the contradiction.
+	Apply(g *Grid) (Step, bool)
+}
+
+// Apply makes the placements and eliminations of the given step.
+// Placing a value also removes it from the peers of its square.
+// Returns an error if the grid is left invalid.
+func (g Grid) Apply(s Step) error {
+	for _, c := range s.Placements {
+		g.squares[c.Cell] &= squareEnum[c.Digit]
+		for _, p := range peers[c.Cell] {
+			g.squares[p] &^= squareEnum[c.Digit]
+		}
+	}
+	for _, c := range s.Eliminations {
+		g.squares[c.Cell] &^= squareEnum[c.Digit]
+	}
+	return g.Check()
+}
+
+// Propagate applies the given techniques to the grid until none of them
+// can refine it any further. After each step, it starts again from the
+// first technique, so the cheapest techniques should be listed first.
+// Returns a *ContradictionError if the grid is invalid, naming the
+// technique whose step revealed the contradiction.
+func (g Grid) Propagate(techniques []Technique) error {
+	if err := g.Check(); err != nil {
+		return err
+	}
+	for {
+		progress := false
+		var (
+			by   Technique
+			step Step
+		)
+		for _, t := range techniques {
+			if s, ok := t.Apply(&g); ok {
+				progress, by, step = true, t, s
+				break
+			}
+		}
+		if !progress {
+			return nil
+		}
+		if err := g.Check(); err != nil {
+			return DetectedBy(err, by, step)
+		}
+	}
+}
+
+// Check returns an error if the grid has a square with no possible value,
+// a unit with two squares defined as the same value, a unit with nowhere
+// left for some value, or a unit with only one square left for more than
+// one value. The error is a *ContradictionError.
+func (g Grid) Check() error {
+	for n, sq := range g.squares {
+		if sq == none {
+			return contradiction(NoCandidates, n, -1, none)
+		}
+	}
+	for u := 0; u < 27; u++ {
+		once, twice, defined := none, none, none
+		for _, c := range unitCells[u] {
+			sq := g.squares[c]
+			if sq.IsDefined() {
+				if defined&sq != none {
+					return contradiction(Duplicate, c, u, sq)
+				}
+				defined |= sq
+			}
+			twice |= once & sq
+			once |= sq
+		}
+		if missing := any &^ once; missing != none {
+			return contradiction(Missing, -1, u, missing)
+		}
+		// values with a single place left cannot share it
+		single := once &^ twice
+		for _, c := range unitCells[u] {
+			if need := g.squares[c] & single; need.Count() > 1 {
+				return contradiction(Missing, c, u, need)
+			}
+		}
+	}
+	return nil
+}
+
+// A Registry is an ordered list of techniques, from the easiest to the
+// hardest. It is safe for concurrent use.
+type Registry struct {
+	mu         sync.RWMutex
+	techniques []Technique
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a technique to the registry, after every technique that
+// is no harder than it.
+// Returns an error if a technique with the same name is already registered.
+func (r *Registry) Register(t Technique) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, x := range r.techniques {
+		if x.Name() == t.Name() {
+			return errors.New("technique " + t.Name() + " is already registered")
+		}
+	}
+	i := sort.Search(len(r.techniques), func(i int) bool {
+		return r.techniques[i].Difficulty() > t.Difficulty()
+	})
+	r.techniques = append(r.techniques, nil)
+	copy(r.techniques[i+1:], r.techniques[i:])
+	r.techniques[i] = t
+	return nil
+}
+
+// Techniques returns the registered techniques, in order.
+func (r *Registry) Techniques() []Technique {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return append([]Technique(nil), r.techniques...)
+}
+
+// TechniquesWith is like Techniques, but the built-in techniques search
+// within the given limits. Other registered techniques are unchanged.
+func (r *Registry) TechniquesWith(l Limits) []Technique {
+	ts := r.Techniques()
+	for i, t := range ts {
+		if b, ok := t.(bounded); ok {
+			ts[i] = bounded{b.builtin, l}
+		}
+	}
+	return ts
+}
+
+// Lookup returns the registered technique with the given name.
+func (r *Registry) Lookup(name string) (Technique, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, t := range r.techniques {
+		if t.Name() == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// DefaultRegistry holds the built-in techniques for every pass, along with
+// any techniques added by Register.
+var DefaultRegistry = NewRegistry()
+
+func init() {
+	for _, t := range BuiltinTechniques(AllPasses) {
+		if err := DefaultRegistry.Register(t); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register adds a technique to the DefaultRegistry.
+// See Registry.Register.
+func Register(t Technique) error {
+	return DefaultRegistry.Register(t)
+}
+
+// BuiltinTechniques returns the techniques behind the given passes,
+// in the order that NormalizeWith tries them, with the default Limits.
+func BuiltinTechniques(p Pass) []Technique {
+	return BuiltinTechniquesWith(p, Limits{})
+}
+
+// BuiltinTechniquesWith is like BuiltinTechniques, but the techniques
+// search within the given limits.
+func BuiltinTechniquesWith(p Pass, l Limits) []Technique {
+	ts := make([]Technique, 0, len(builtins))
+	for _, t := range builtins {
+		if p&t.pass != 0 {
+			ts = append(ts, bounded{t, l})
+		}
+	}
+	return ts
+}
+
+// PassOf returns the pass behind a built-in technique, or zero for any
+// other technique.
+func PassOf(t Technique) Pass {
+	if b, ok := t.(bounded); ok {
+		return b.pass
+	}
+	return 0
+}
+
+// A builtin is the technique behind one of the passes.
+type builtin struct {
+	pass       Pass
+	name       string
+	difficulty float64
+	find       func(Grid, Limits) (pattern, bool)
+}
+
+func (t *builtin) Name() string {
+	return t.name
+}
+
+func (t *builtin) Difficulty() float64 {
+	return t.difficulty
+}
+
+// A bounded technique is a builtin that searches within the given limits.
+type bounded struct {
+	*builtin
+	limits Limits
+}
+
+func (t bounded) Apply(g *Grid) (Step, bool) {
+	p, ok := t.find(*g, t.limits)
+	if !ok {
+		return Step{}, false
+	}
+	s := g.stepFor(p)
+	if s.Difficulty == 0 {
+		s.Difficulty = t.difficulty
+	}
+	// a contradiction is left in the grid for the caller to find
+	_ = g.Apply(s)
+	return s, true
+}
+
+// A single is the placement of a naked or hidden single, which is a step
+// in its own right.
+type single struct {
+	Step
+}
+
+func (s single) Name() string {
+	return s.Technique
+}
+
+// findNakedSingle looks for a square with a single possible value that is
+// still a candidate in one of its peers.
+func (g Grid) findNakedSingle() (single, bool) {
+	for n, sq := range g.squares {
+		if !sq.IsDefined() {
+			continue
+		}
+		for _, p := range peers[n] {
+			if g.squares[p]&sq == none {
+				continue
+			}
+			c := Candidate{n, sq.Value()}
+			return single{Step{
+				Technique:   "Naked Single",
+				Placements:  []Candidate{c},
+				Cells:       []int{n},
+				Description: "Naked Single: " + Inference{c, true}.String(),
+			}}, true
+		}
+	}
+	return single{}, false
+}
+
+// findHiddenSingle looks for a value that has only one possible position
+// in some unit, where the square is not yet defined. Blocks are searched
+// before rows and columns, since their hidden singles are easier to see.
+func (g Grid) findHiddenSingle() (single, bool) {
+	for i := 0; i < 27; i++ {
+		u := (i + 18) % 27
+		once, twice := none, none
+		for _, c := range unitCells[u] {
+			sq := g.squares[c]
+			twice |= once & sq
+			once |= sq
+		}
+		lone := once &^ twice
+		if lone == none {
+			continue
+		}
+		for _, n := range unitCells[u] {
+			sq := g.squares[n]
+			if sq.IsDefined() || sq&lone == none {
+				continue
+			}
+			unit := unitByID(u)
+			c := Candidate{n, (sq & lone).Values()[0]}
+			difficulty := 1.5
+			if unit.Kind == Block {
+				difficulty = 1.2
+			}
+			return single{Step{
+				Technique:   "Hidden Single",
+				Difficulty:  difficulty,
+				Placements:  []Candidate{c},
+				Cells:       []int{n},
+				Units:       []Unit{unit},
+				Description: "Hidden Single: " + Inference{c, true}.String() + " in " + unit.String(),
+			}}, true
+		}
+	}
+	return single{}, false
+}
+
+// A pattern is one of the results of the built-in techniques.
+type pattern interface {
+	Name() string
+	String() string
+}
+
+// stepFor builds the step that removes the eliminations of a pattern,
+// listing the squares and units that make it up.
+func (g Grid) stepFor(p pattern) Step {
+	s := Step{Technique: p.Name(), Description: p.String()}
+	switch p := p.(type) {
+	case single:
+		return p.Step
+	case LockedCandidates:
+		s.Eliminations = p.Eliminations
+		s.Cells = p.Cells
+		s.Units = []Unit{p.Base, p.Cover}
+	case Subset:
+		s.Eliminations = p.Eliminations
+		s.Cells = p.Cells
+		s.Units = []Unit{p.Unit}
+	case Fish:
+		s.Eliminations = p.Eliminations
+		for _, u := range p.Base {
+			for _, c := range g.positions(u.id(), p.Digit) {
+				if !containsInt(p.Fins, c) {
+					s.Cells = append(s.Cells, c)
+				}
+			}
+		}
+		s.Units = append(append([]Unit(nil), p.Base...), p.Cover...)
+		s.Digit, s.Base, s.Cover, s.Fins = p.Digit, p.Base, p.Cover, p.Fins
+	case Wing:
+		s.Eliminations = p.Eliminations
+		s.Cells = append(append([]int(nil), p.Pivot...), p.Pincers...)
+		s.Pivot, s.Pincers = p.Pivot, p.Pincers
+		if p.Kind == WWing {
+			s.Digit = p.Link
+		}
+	case SingleDigitPattern:
+		s.Eliminations = p.Eliminations
+		s.Cells = p.Cells
+		s.Units = p.Units
+		s.Digit = p.Digit
+	case Coloring:
+		s.Eliminations = p.Eliminations
+		for _, cells := range p.Colors {
+			s.Cells = append(s.Cells, cells...)
+		}
+		s.Digit, s.Colors = p.Digit, p.Colors
+	case Chain:
+		s.Eliminations = p.Eliminations
+		for _, n := range p.Nodes {
+			s.Cells = appendCell(s.Cells, n.Cell)
+		}
+	case DeadlyPattern:
+		s.Eliminations = p.Eliminations
+		s.Cells = p.Cells
+	case ALSPattern:
+		s.Eliminations = p.Eliminations
+		if p.Kind == DeathBlossom {
+			s.Cells = []int{p.Stem}
+		}
+		for _, a := range p.Sets {
+			for _, c := range a.Cells {
+				s.Cells = appendCell(s.Cells, c)
+			}
+			s.Units = append(s.Units, a.Unit)
+		}
+	case SueDeCoq:
+		s.Eliminations = p.Eliminations
+		s.Cells = append(append(append([]int(nil), p.Cells...), p.LineCells...), p.BlockCells...)
+		s.Units = []Unit{p.Line, p.Block}
+	case ForcingChain:
+		s.Eliminations = p.Eliminations
+		for _, br := range p.Branches {
+			s.Cells = appendCell(s.Cells, br.Assumption.Cell)
+		}
+		s.Proof = p.Branches
+		if p.Kind == UnitForcing {
+			s.Units = []Unit{p.Unit}
+		}
+	}
+	return s
+}
+
+// appendCell appends c to cells, unless it is already there.
+func appendCell(cells []int, c int) []int {
+	if containsInt(cells, c) {
+		return cells
+	}
+	return append(cells, c)
+}
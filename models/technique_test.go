@@ -0,0 +1,229 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// houseRule is a technique that makes a fixed step, once.
+type houseRule struct {
+	name       string
+	difficulty float64
+	step       Step
+}
+
+func (h houseRule) Name() string        { return h.name }
+func (h houseRule) Difficulty() float64 { return h.difficulty }
+
+func (h houseRule) Apply(g *Grid) (Step, bool) {
+	for _, c := range h.step.Eliminations {
+		if g.Get(c.Cell).Has(c.Digit) {
+			_ = g.Apply(h.step)
+			return h.step, true
+		}
+	}
+	return Step{}, false
+}
+
+func TestBuiltinTechniques(t *testing.T) {
+	a := assert.New(t)
+
+	basic := BuiltinTechniques(BasicPasses)
+	if a.Len(basic, 2) {
+		a.Equal("Hidden Single", basic[0].Name())
+		a.Equal("Naked Single", basic[1].Name())
+	}
+
+	all := BuiltinTechniques(AllPasses)
+	a.Len(all, len(builtins))
+	for i := 1; i < len(all); i++ {
+		a.LessOrEqual(all[i-1].Difficulty(), all[i].Difficulty(), all[i].Name())
+	}
+	a.Equal(all, DefaultRegistry.Techniques())
+}
+
+func TestRegistry(t *testing.T) {
+	a, r := assert.New(t), require.New(t)
+
+	reg := NewRegistry()
+	r.NoError(reg.Register(houseRule{name: "c", difficulty: 3}))
+	r.NoError(reg.Register(houseRule{name: "a", difficulty: 1}))
+	r.NoError(reg.Register(houseRule{name: "b1", difficulty: 2}))
+	r.NoError(reg.Register(houseRule{name: "b2", difficulty: 2}))
+	a.Error(reg.Register(houseRule{name: "a", difficulty: 4}))
+
+	var names []string
+	for _, x := range reg.Techniques() {
+		names = append(names, x.Name())
+	}
+	a.Equal([]string{"a", "b1", "b2", "c"}, names)
+
+	got, ok := reg.Lookup("b2")
+	a.True(ok)
+	a.Equal(2.0, got.Difficulty())
+	_, ok = reg.Lookup("d")
+	a.False(ok)
+}
+
+func TestRegistryTechniquesWith(t *testing.T) {
+	a, r := assert.New(t), require.New(t)
+
+	reg := NewRegistry()
+	for _, x := range BuiltinTechniques(ForcingPasses) {
+		r.NoError(reg.Register(x))
+	}
+	extra := houseRule{name: "House Rule", difficulty: 1}
+	r.NoError(reg.Register(extra))
+
+	l := Limits{ChainDepth: 1}
+	ts := reg.TechniquesWith(l)
+	r.Len(ts, len(reg.Techniques()))
+	a.Equal(extra, ts[0])
+	for _, x := range ts[1:] {
+		b, ok := x.(bounded)
+		r.True(ok, x.Name())
+		a.Equal(l, b.limits)
+	}
+	// the registry itself is unchanged
+	for _, x := range reg.Techniques()[1:] {
+		a.Equal(Limits{}, x.(bounded).limits)
+	}
+}
+
+func TestApply(t *testing.T) {
+	a := assert.New(t)
+
+	g := candidateGrid(map[int][]int{0: {1, 2}, 1: {3}})
+	a.NoError(g.Apply(Step{Eliminations: []Candidate{{0, 1}, {0, 3}, {2, 9}}}))
+	a.Equal(two, g.squares[0])
+	a.Equal(any&^nine, g.squares[2])
+
+	a.NoError(g.Apply(Step{Placements: []Candidate{{40, 7}}}))
+	a.Equal(seven, g.squares[40])
+	for _, p := range peers[40] {
+		a.False(g.Get(p).Has(7), CellName(p))
+	}
+
+	var got *ContradictionError
+	err := g.Apply(Step{Eliminations: []Candidate{{1, 3}}})
+	if a.True(errors.As(err, &got)) {
+		a.Equal(NoCandidates, got.Kind)
+		a.Equal(1, got.Cell)
+	}
+}
+
+func TestFindNakedSingle(t *testing.T) {
+	a := assert.New(t)
+
+	g := candidateGrid(map[int][]int{40: {7}})
+	s, ok := g.findNakedSingle()
+	if a.True(ok) {
+		a.Equal("Naked Single", s.Technique)
+		a.Equal([]Candidate{{40, 7}}, s.Placements)
+		a.Equal("Naked Single: r5c5=7", s.String())
+	}
+
+	a.NoError(g.Apply(s.Step))
+	for _, p := range peers[40] {
+		a.False(g.Get(p).Has(7), CellName(p))
+	}
+	_, ok = g.findNakedSingle()
+	a.False(ok)
+}
+
+func TestFindHiddenSingle(t *testing.T) {
+	a := assert.New(t)
+
+	// 3 can only go in r1c1 within block 1
+	restrict := map[int][]int{}
+	for _, c := range unitCells[18] {
+		if c != 0 {
+			restrict[c] = without(3)
+		}
+	}
+	s, ok := candidateGrid(restrict).findHiddenSingle()
+	if a.True(ok) {
+		a.Equal([]Candidate{{0, 3}}, s.Placements)
+		a.Equal([]Unit{{Block, 0}}, s.Units)
+		a.Equal(1.2, s.Difficulty)
+		a.Equal("Hidden Single: r1c1=3 in block 1", s.String())
+	}
+
+	// 3 can only go in r1c1 within row 1
+	restrict = map[int][]int{}
+	for c := 1; c < 9; c++ {
+		restrict[c] = without(3)
+	}
+	s, ok = candidateGrid(restrict).findHiddenSingle()
+	if a.True(ok) {
+		a.Equal([]Candidate{{0, 3}}, s.Placements)
+		a.Equal(1.5, s.Difficulty)
+	}
+
+	_, ok = candidateGrid(nil).findHiddenSingle()
+	a.False(ok)
+}
+
+func TestPropagate(t *testing.T) {
+	a, r := assert.New(t), require.New(t)
+
+	const puzzle = "000000023600010000000400000000080700502000000000000100080203000010000640000500000"
+
+	// the basic techniques get stuck on this puzzle
+	g := NewGrid([]byte(puzzle))
+	r.NoError(g.Propagate(BuiltinTechniques(BasicPasses)))
+	a.True(g.Get(0).Has(7))
+
+	// a house rule can be mixed in with the built-in techniques
+	rule := houseRule{
+		name:       "Oracle",
+		difficulty: 1,
+		step: Step{
+			Technique:    "Oracle",
+			Eliminations: []Candidate{{0, 7}},
+		},
+	}
+	reg := NewRegistry()
+	for _, x := range BuiltinTechniques(BasicPasses) {
+		r.NoError(reg.Register(x))
+	}
+	r.NoError(reg.Register(rule))
+	a.Equal("Oracle", reg.Techniques()[0].Name())
+
+	g = NewGrid([]byte(puzzle))
+	r.NoError(g.Propagate(reg.Techniques()))
+	a.False(g.Get(0).Has(7))
+}
+
+func TestPropagateContradiction(t *testing.T) {
+	a := assert.New(t)
+
+	// two 5s in row 1
+	g := NewGrid([]byte("55" + strings81('.')[2:]))
+	a.Error(g.Propagate(BuiltinTechniques(BasicPasses)))
+
+	// a rule that removes the last value of a square
+	g = candidateGrid(map[int][]int{0: {1}, 1: {1, 2}})
+	rule := houseRule{name: "Bad", step: Step{Eliminations: []Candidate{{1, 2}}}}
+	a.Error(g.Propagate([]Technique{rule}))
+
+	a.NoError(candidateGrid(nil).Propagate(nil))
+}
+
+func TestStepFor(t *testing.T) {
+	a := assert.New(t)
+
+	g := candidateGrid(map[int][]int{3: {1, 2}, 7: {1, 2}})
+	sub, ok := g.findNakedSubset(2)
+	if a.True(ok) {
+		s := g.stepFor(sub)
+		a.Equal("Naked Pair", s.Technique)
+		a.Equal(sub.String(), s.Description)
+		a.Equal([]int{3, 7}, s.Cells)
+		a.Equal([]Unit{{Row, 0}}, s.Units)
+		a.Equal(sub.Eliminations, s.Eliminations)
+	}
+}
@@ -0,0 +1,416 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// AssumeUnique returns a copy of the grid, sharing the same squares, which
+// asserts that the puzzle has exactly one solution. This enables the
+// UniquenessPasses, which are unsound for puzzles with more than one
+// solution, and do nothing for grids that have not made this assertion.
+func (g Grid) AssumeUnique() Grid {
+	g.unique = true
+	return g
+}
+
+// AssumesUnique reports whether the grid asserts that the puzzle has
+// exactly one solution.
+func (g Grid) AssumesUnique() bool {
+	return g.unique
+}
+
+// A DeadlyPatternKind identifies one of the uniqueness techniques.
+type DeadlyPatternKind int
+
+const (
+	// UniqueRectangle1 is a rectangle in which three corners hold only the
+	// same two values, so the fourth corner cannot be either of them.
+	UniqueRectangle1 DeadlyPatternKind = iota
+	// UniqueRectangle2 is a rectangle in which two corners that share a
+	// row or column have the same extra value, which must be in one of
+	// them.
+	UniqueRectangle2
+	// UniqueRectangle3 is a rectangle whose extra values, in two corners
+	// that share a unit, form a naked subset with other squares in it.
+	UniqueRectangle3
+	// UniqueRectangle4 is a rectangle in which one of the two values can
+	// only go in the two corners that share a unit, so the other value
+	// cannot go in either of them.
+	UniqueRectangle4
+	// UniqueRectangle5 is a rectangle in which two diagonal corners, or
+	// three corners, have the same extra value, which must be in one of
+	// them.
+	UniqueRectangle5
+	// UniqueRectangle6 is a rectangle with extra values in two diagonal
+	// corners, in which one of the values can only go in the rectangle
+	// in both rows and both columns, so it cannot go in those corners.
+	UniqueRectangle6
+	// HiddenUniqueRectangle is a rectangle with one bivalue corner, where
+	// one of the values can only go in the rectangle in the row and column
+	// of the opposite corner, so the other value cannot go there.
+	HiddenUniqueRectangle
+	// BUGPlusOne is a grid in which every undefined square is bivalue
+	// except one, which must hold the value that would otherwise appear
+	// three times in its units.
+	BUGPlusOne
+)
+
+// String implements the fmt.Stringer interface
+func (k DeadlyPatternKind) String() string {
+	switch {
+	case k >= UniqueRectangle1 && k <= UniqueRectangle6:
+		return "Unique Rectangle Type " + strconv.Itoa(int(k-UniqueRectangle1)+1)
+	case k == HiddenUniqueRectangle:
+		return "Hidden Unique Rectangle"
+	case k == BUGPlusOne:
+		return "BUG+1"
+	default:
+		return "Deadly Pattern"
+	}
+}
+
+// A DeadlyPattern describes an elimination that avoids a deadly pattern:
+// an arrangement of values that could be swapped to give a second
+// solution. Since the puzzle is assumed to have only one solution, the
+// deadly pattern cannot appear.
+//
+// For a unique rectangle, Digits holds the two values of the rectangle
+// and Cells its four corners, in the order r1c1, r1c2, r2c1, r2c2.
+// For BUG+1, Digits holds the value of the extra square, which is the
+// only square in Cells.
+type DeadlyPattern struct {
+	Kind         DeadlyPatternKind
+	Digits       []int
+	Cells        []int
+	Eliminations []Candidate
+}
+
+// Name returns the conventional name of the technique,
+// e.g. "Unique Rectangle Type 1"
+func (p DeadlyPattern) Name() string {
+	return p.Kind.String()
+}
+
+// String implements the fmt.Stringer interface
+func (p DeadlyPattern) String() string {
+	var b strings.Builder
+	b.WriteString(p.Name())
+	b.WriteString(": ")
+	for i, d := range p.Digits {
+		if i > 0 {
+			b.WriteByte('/')
+		}
+		b.WriteString(strconv.Itoa(d))
+	}
+	b.WriteString(" in ")
+	b.WriteString(formatCells(p.Cells))
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(p.Eliminations))
+	return b.String()
+}
+
+// findUniqueRectangle looks for a unique rectangle of types 1 to 6 that
+// allows at least one candidate to be eliminated. Returns false unless the
+// grid assumes that the puzzle is unique.
+func (g Grid) findUniqueRectangle() (DeadlyPattern, bool) {
+	if !g.unique {
+		return DeadlyPattern{}, false
+	}
+	var found DeadlyPattern
+	g.forEachRectangle(func(cells [4]int, pair Square) bool {
+		var ok bool
+		found, ok = g.uniqueRectangle(cells, pair)
+		return !ok
+	})
+	return found, found.Cells != nil
+}
+
+// findHiddenUniqueRectangle looks for a hidden unique rectangle that allows
+// at least one candidate to be eliminated. Returns false unless the grid
+// assumes that the puzzle is unique.
+func (g Grid) findHiddenUniqueRectangle() (DeadlyPattern, bool) {
+	if !g.unique {
+		return DeadlyPattern{}, false
+	}
+	var found DeadlyPattern
+	g.forEachRectangle(func(cells [4]int, pair Square) bool {
+		for i, a := range cells {
+			if g.squares[a] != pair {
+				continue
+			}
+			// the opposite corner, and the corners sharing its row and column
+			d := cells[3-i]
+			rowMate, colMate := cells[(3-i)^1], cells[(3-i)^2]
+			for _, x := range pair.Values() {
+				if !g.onlyAt(cellUnits[d][0], x, d, rowMate) || !g.onlyAt(cellUnits[d][1], x, d, colMate) {
+					continue
+				}
+				y := (pair &^ squareEnum[x]).Value()
+				found = g.deadlyPattern(HiddenUniqueRectangle, pair, cells[:], []Candidate{{d, y}})
+				if found.Cells != nil {
+					return false
+				}
+			}
+		}
+		return true
+	})
+	return found, found.Cells != nil
+}
+
+// findBUGPlusOne looks for a bivalue universal grave with a single extra
+// square. Returns false unless the grid assumes that the puzzle is unique.
+func (g Grid) findBUGPlusOne() (DeadlyPattern, bool) {
+	if !g.unique {
+		return DeadlyPattern{}, false
+	}
+	extra := -1
+	for n := 0; n < 81; n++ {
+		switch sq := g.squares[n]; {
+		case sq.IsDefined() || sq.Count() == 2:
+		case sq.Count() == 3 && extra < 0:
+			extra = n
+		default:
+			return DeadlyPattern{}, false
+		}
+	}
+	if extra < 0 {
+		return DeadlyPattern{}, false
+	}
+
+	// every value must appear twice in each unit, except for one value,
+	// which appears three times in each unit of the extra square
+	digit := 0
+	for u := 0; u < 27; u++ {
+		for d := 1; d <= 9; d++ {
+			switch pos := g.positions(u, d); {
+			case len(pos) == 0 || len(pos) == 2:
+			case len(pos) == 3 && containsInt(pos, extra) && (digit == 0 || digit == d):
+				digit = d
+			default:
+				return DeadlyPattern{}, false
+			}
+		}
+	}
+	if digit == 0 {
+		return DeadlyPattern{}, false
+	}
+	for _, u := range cellUnits[extra] {
+		if len(g.positions(u, digit)) != 3 {
+			return DeadlyPattern{}, false
+		}
+	}
+
+	var elims []Candidate
+	for _, d := range g.squares[extra].Values() {
+		if d != digit {
+			elims = append(elims, Candidate{extra, d})
+		}
+	}
+	return DeadlyPattern{
+		Kind:         BUGPlusOne,
+		Digits:       []int{digit},
+		Cells:        []int{extra},
+		Eliminations: elims,
+	}, true
+}
+
+// forEachRectangle calls fn for each rectangle of undefined squares that
+// spans exactly two blocks, and for each pair of values that all four
+// corners share, until fn returns false.
+func (g Grid) forEachRectangle(fn func(cells [4]int, pair Square) bool) {
+	for r1 := 0; r1 < 9; r1++ {
+		for r2 := r1 + 1; r2 < 9; r2++ {
+			for c1 := 0; c1 < 9; c1++ {
+				for c2 := c1 + 1; c2 < 9; c2++ {
+					if (r1/3 == r2/3) == (c1/3 == c2/3) {
+						continue
+					}
+					cells := [4]int{r1*9 + c1, r1*9 + c2, r2*9 + c1, r2*9 + c2}
+					common := any
+					for _, c := range cells {
+						common &= g.squares[c]
+						if g.squares[c].IsDefined() {
+							common = none
+						}
+					}
+					if common.Count() < 2 {
+						continue
+					}
+					stop := false
+					forEachCombination(common.Values(), 2, func(ab []int) bool {
+						stop = !fn(cells, squareEnum[ab[0]]|squareEnum[ab[1]])
+						return !stop
+					})
+					if stop {
+						return
+					}
+				}
+			}
+		}
+	}
+}
+
+// uniqueRectangle tries each type of unique rectangle in turn on the given
+// corners, which all share the values in pair.
+func (g Grid) uniqueRectangle(cells [4]int, pair Square) (DeadlyPattern, bool) {
+	var floor, roof []int
+	for _, c := range cells {
+		if g.squares[c] == pair {
+			floor = append(floor, c)
+		} else {
+			roof = append(roof, c)
+		}
+	}
+
+	switch len(roof) {
+	case 1:
+		return g.urPattern(UniqueRectangle1, pair, cells, g.candidatesIn(roof, pair))
+	case 2, 3:
+		if p, ok := g.urSingleExtra(pair, cells, roof); ok {
+			return p, true
+		}
+	}
+	if len(roof) != 2 {
+		return DeadlyPattern{}, false
+	}
+
+	shared := sharedUnits(roof[0], roof[1])
+	if len(shared) == 0 {
+		// the roof is diagonal
+		for _, x := range pair.Values() {
+			if g.onlyInRectangle(cells, x) {
+				return g.urPattern(UniqueRectangle6, pair, cells, g.candidatesIn(roof, squareEnum[x]))
+			}
+		}
+		return DeadlyPattern{}, false
+	}
+
+	for _, u := range shared {
+		for _, x := range pair.Values() {
+			if g.onlyAt(u, x, roof[0], roof[1]) {
+				y := pair &^ squareEnum[x]
+				if p, ok := g.urPattern(UniqueRectangle4, pair, cells, g.candidatesIn(roof, y)); ok {
+					return p, true
+				}
+			}
+		}
+	}
+	extras := (g.squares[roof[0]] | g.squares[roof[1]]) &^ pair
+	for _, u := range shared {
+		if elims := g.urNakedSubset(u, roof, extras); len(elims) > 0 {
+			return g.urPattern(UniqueRectangle3, pair, cells, elims)
+		}
+	}
+	return DeadlyPattern{}, false
+}
+
+// urSingleExtra handles types 2 and 5, where every roof square has the
+// same single extra value, which must go in one of them.
+func (g Grid) urSingleExtra(pair Square, cells [4]int, roof []int) (DeadlyPattern, bool) {
+	extra := g.squares[roof[0]] &^ pair
+	if extra.Count() != 1 {
+		return DeadlyPattern{}, false
+	}
+	for _, c := range roof[1:] {
+		if g.squares[c]&^pair != extra {
+			return DeadlyPattern{}, false
+		}
+	}
+	kind := UniqueRectangle5
+	if len(roof) == 2 && len(sharedUnits(roof[0], roof[1])) > 0 {
+		kind = UniqueRectangle2
+	}
+	return g.urPattern(kind, pair, cells, g.eliminationsSeeing(extra.Value(), roof))
+}
+
+// urNakedSubset looks for squares in unit u, which together with the extra
+// values of the roof, form a naked subset. Returns the candidates that can
+// be removed from the rest of the unit.
+func (g Grid) urNakedSubset(u int, roof []int, extras Square) []Candidate {
+	var others []int
+	for _, c := range g.openCells(u) {
+		if !containsInt(roof, c) {
+			others = append(others, c)
+		}
+	}
+	var elims []Candidate
+	for size := 1; size < len(others) && elims == nil; size++ {
+		forEachCombination(others, size, func(subset []int) bool {
+			union := extras
+			for _, c := range subset {
+				union |= g.squares[c]
+			}
+			if union.Count() != size+1 {
+				return true
+			}
+			for _, c := range others {
+				if containsInt(subset, c) {
+					continue
+				}
+				for _, d := range (g.squares[c] & union).Values() {
+					elims = append(elims, Candidate{c, d})
+				}
+			}
+			return elims == nil
+		})
+	}
+	return elims
+}
+
+// urPattern builds a unique rectangle with the given eliminations.
+// Returns false if there is nothing to eliminate.
+func (g Grid) urPattern(kind DeadlyPatternKind, pair Square, cells [4]int, elims []Candidate) (DeadlyPattern, bool) {
+	p := g.deadlyPattern(kind, pair, cells[:], elims)
+	return p, p.Cells != nil
+}
+
+func (g Grid) deadlyPattern(kind DeadlyPatternKind, pair Square, cells []int, elims []Candidate) DeadlyPattern {
+	if len(elims) == 0 {
+		return DeadlyPattern{}
+	}
+	return DeadlyPattern{
+		Kind:         kind,
+		Digits:       pair.Values(),
+		Cells:        append([]int(nil), cells...),
+		Eliminations: elims,
+	}
+}
+
+// candidatesIn returns the values of mask that the given cells could hold.
+func (g Grid) candidatesIn(cells []int, mask Square) []Candidate {
+	var out []Candidate
+	for _, c := range cells {
+		for _, d := range (g.squares[c] & mask).Values() {
+			out = append(out, Candidate{c, d})
+		}
+	}
+	return out
+}
+
+// onlyAt reports whether, in unit u, the value d can only go in squares
+// a and b.
+func (g Grid) onlyAt(u, d, a, b int) bool {
+	pos := g.positions(u, d)
+	return len(pos) == 2 && containsInt(pos, a) && containsInt(pos, b)
+}
+
+// onlyInRectangle reports whether, in both rows and both columns of the
+// rectangle, the value d can only go in its corners.
+func (g Grid) onlyInRectangle(cells [4]int, d int) bool {
+	return g.onlyAt(cellUnits[cells[0]][0], d, cells[0], cells[1]) &&
+		g.onlyAt(cellUnits[cells[2]][0], d, cells[2], cells[3]) &&
+		g.onlyAt(cellUnits[cells[0]][1], d, cells[0], cells[2]) &&
+		g.onlyAt(cellUnits[cells[1]][1], d, cells[1], cells[3])
+}
+
+// sharedUnits returns the ids of the units that contain both a and b.
+func sharedUnits(a, b int) []int {
+	var units []int
+	for k := 0; k < 3; k++ {
+		if cellUnits[a][k] == cellUnits[b][k] {
+			units = append(units, cellUnits[a][k])
+		}
+	}
+	return units
+}
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindUniqueRectangle(t *testing.T) {
+	// the rectangle r1c1,r1c4,r2c1,r2c4 on the values 1 and 2
+	type4 := onlyIn(map[int][]int{}, 1, []int{1}, []int{0, 3})
+	type4[0], type4[3] = []int{1, 2}, []int{1, 2}
+
+	type6 := onlyIn(map[int][]int{}, 1, []int{0, 1}, []int{0, 3})
+	type6 = transpose(onlyIn(transpose(type6), 1, []int{0, 3}, []int{0, 1}))
+	type6[0], type6[12] = []int{1, 2}, []int{1, 2}
+
+	tt := []struct {
+		name string
+		grid Grid
+		want DeadlyPattern
+		desc string
+	}{
+		{
+			name: "type 1",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 3: {1, 2}, 9: {1, 2}}),
+			want: DeadlyPattern{
+				Kind:         UniqueRectangle1,
+				Digits:       []int{1, 2},
+				Cells:        []int{0, 3, 9, 12},
+				Eliminations: []Candidate{{12, 1}, {12, 2}},
+			},
+			desc: "Unique Rectangle Type 1: 1/2 in r1c1,r1c4,r2c1,r2c4 => r2c4<>1, r2c4<>2",
+		},
+		{
+			name: "type 2",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 3: {1, 2}, 9: {1, 2, 5}, 12: {1, 2, 5}}),
+			want: DeadlyPattern{
+				Kind:   UniqueRectangle2,
+				Digits: []int{1, 2},
+				Cells:  []int{0, 3, 9, 12},
+				Eliminations: []Candidate{
+					{10, 5}, {11, 5}, {13, 5}, {14, 5}, {15, 5}, {16, 5}, {17, 5},
+				},
+			},
+			desc: "Unique Rectangle Type 2: 1/2 in r1c1,r1c4,r2c1,r2c4 => " +
+				"r2c2<>5, r2c3<>5, r2c5<>5, r2c6<>5, r2c7<>5, r2c8<>5, r2c9<>5",
+		},
+		{
+			name: "type 3",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 3: {1, 2}, 9: {1, 2, 5}, 12: {1, 2, 6}, 10: {5, 6}}),
+			want: DeadlyPattern{
+				Kind:   UniqueRectangle3,
+				Digits: []int{1, 2},
+				Cells:  []int{0, 3, 9, 12},
+				Eliminations: []Candidate{
+					{11, 5}, {11, 6}, {13, 5}, {13, 6}, {14, 5}, {14, 6}, {15, 5}, {15, 6},
+					{16, 5}, {16, 6}, {17, 5}, {17, 6},
+				},
+			},
+			desc: "Unique Rectangle Type 3: 1/2 in r1c1,r1c4,r2c1,r2c4 => " +
+				"r2c3<>5, r2c3<>6, r2c5<>5, r2c5<>6, r2c6<>5, r2c6<>6, " +
+				"r2c7<>5, r2c7<>6, r2c8<>5, r2c8<>6, r2c9<>5, r2c9<>6",
+		},
+		{
+			name: "type 4",
+			grid: candidateGrid(type4),
+			want: DeadlyPattern{
+				Kind:         UniqueRectangle4,
+				Digits:       []int{1, 2},
+				Cells:        []int{0, 3, 9, 12},
+				Eliminations: []Candidate{{9, 2}, {12, 2}},
+			},
+			desc: "Unique Rectangle Type 4: 1/2 in r1c1,r1c4,r2c1,r2c4 => r2c1<>2, r2c4<>2",
+		},
+		{
+			name: "type 5",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 12: {1, 2}, 3: {1, 2, 5}, 9: {1, 2, 5}}),
+			want: DeadlyPattern{
+				Kind:         UniqueRectangle5,
+				Digits:       []int{1, 2},
+				Cells:        []int{0, 3, 9, 12},
+				Eliminations: []Candidate{{1, 5}, {2, 5}, {13, 5}, {14, 5}},
+			},
+			desc: "Unique Rectangle Type 5: 1/2 in r1c1,r1c4,r2c1,r2c4 => " +
+				"r1c2<>5, r1c3<>5, r2c5<>5, r2c6<>5",
+		},
+		{
+			name: "type 6",
+			grid: candidateGrid(type6),
+			want: DeadlyPattern{
+				Kind:         UniqueRectangle6,
+				Digits:       []int{1, 2},
+				Cells:        []int{0, 3, 9, 12},
+				Eliminations: []Candidate{{3, 1}, {9, 1}},
+			},
+			desc: "Unique Rectangle Type 6: 1/2 in r1c1,r1c4,r2c1,r2c4 => r1c4<>1, r2c1<>1",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			_, ok := tc.grid.findUniqueRectangle()
+			assert.False(t, ok, "uniqueness has not been asserted")
+
+			got, ok := tc.grid.AssumeUnique().findUniqueRectangle()
+			require.True(t, ok)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.desc, got.String())
+		})
+	}
+}
+
+func TestFindHiddenUniqueRectangle(t *testing.T) {
+	restrict := onlyIn(map[int][]int{}, 1, []int{1}, []int{0, 3})
+	restrict = transpose(onlyIn(transpose(restrict), 1, []int{3}, []int{0, 1}))
+	restrict[0] = []int{1, 2}
+	g := candidateGrid(restrict)
+
+	_, ok := g.findHiddenUniqueRectangle()
+	assert.False(t, ok, "uniqueness has not been asserted")
+
+	got, ok := g.AssumeUnique().findHiddenUniqueRectangle()
+	require.True(t, ok)
+	assert.Equal(t, DeadlyPattern{
+		Kind:         HiddenUniqueRectangle,
+		Digits:       []int{1, 2},
+		Cells:        []int{0, 3, 9, 12},
+		Eliminations: []Candidate{{12, 2}},
+	}, got)
+}
+
+func TestFindBUGPlusOne(t *testing.T) {
+	g := NewGrid([]byte("000009300400080000000000200020600001031000000000050040600100070000000800000300000"))
+	require.NoError(t, g.NormalizeWith(BasicPasses|SubsetPasses|IntersectionPasses))
+
+	_, ok := g.findBUGPlusOne()
+	assert.False(t, ok, "uniqueness has not been asserted")
+
+	got, ok := g.AssumeUnique().findBUGPlusOne()
+	require.True(t, ok)
+	assert.Equal(t, "BUG+1: 5 in r3c3 => r3c3<>7, r3c3<>9", got.String())
+}
+
+func TestAssumeUnique(t *testing.T) {
+	g := NewGrid([]byte(strings81('.')))
+	assert.False(t, g.AssumesUnique())
+
+	u := g.AssumeUnique()
+	assert.True(t, u.AssumesUnique())
+	assert.True(t, u.Clone().AssumesUnique())
+	assert.False(t, g.AssumesUnique(), "the original grid is unchanged")
+}
@@ -0,0 +1,115 @@
+package models
+
+import "strconv"
+
+// A UnitKind says whether a unit is a row, a column or a 3x3 block.
+type UnitKind int
+
+const (
+	Row UnitKind = iota
+	Column
+	Block
+)
+
+// String implements the fmt.Stringer interface
+func (k UnitKind) String() string {
+	switch k {
+	case Row:
+		return "row"
+	case Column:
+		return "column"
+	case Block:
+		return "block"
+	default:
+		return "unit"
+	}
+}
+
+// A Unit is one of the 27 groups of nine squares (rows, columns and blocks)
+// that must each contain every digit exactly once.
+// Index is numbered from 0 to 8: top to bottom for rows, left to right
+// for columns, and left to right then top to bottom for blocks.
+type Unit struct {
+	Kind  UnitKind `json:"kind"`
+	Index int      `json:"index"`
+}
+
+// String implements the fmt.Stringer interface.
+// Units are numbered from 1 for display, e.g. "row 1" or "block 9".
+func (u Unit) String() string {
+	return u.Kind.String() + " " + strconv.Itoa(u.Index+1)
+}
+
+// Cells returns the indices of the nine squares in this unit, in order.
+func (u Unit) Cells() [9]int {
+	return unitCells[u.id()]
+}
+
+// id numbers the units from 0 to 26: rows, then columns, then blocks.
+func (u Unit) id() int {
+	return int(u.Kind)*9 + u.Index
+}
+
+// unitByID is the inverse of Unit.id
+func unitByID(id int) Unit {
+	return Unit{Kind: UnitKind(id / 9), Index: id % 9}
+}
+
+// UnitsOf returns the row, column and block that contain square n.
+func UnitsOf(n int) [3]Unit {
+	return [3]Unit{
+		{Row, n / 9},
+		{Column, n % 9},
+		{Block, (n/27)*3 + (n%9)/3},
+	}
+}
+
+// CellName returns the conventional name for square n, e.g. "r1c1" for the
+// top left square and "r9c9" for the bottom right.
+func CellName(n int) string {
+	return "r" + strconv.Itoa(n/9+1) + "c" + strconv.Itoa(n%9+1)
+}
+
+// Sees reports whether squares a and b are distinct squares that share
+// a row, column or block.
+func Sees(a, b int) bool {
+	if a == b {
+		return false
+	}
+	return a/9 == b/9 || a%9 == b%9 ||
+		(a/27 == b/27 && (a%9)/3 == (b%9)/3)
+}
+
+var (
+	// unitCells lists the squares of each unit, indexed by Unit.id()
+	unitCells [27][9]int
+	// cellUnits lists the ids of the row, column and block of each square
+	cellUnits [81][3]int
+	// peers lists the 20 other squares that share a unit with each square
+	peers [81][20]int
+	// peerSets holds the same squares as peers, as a cellSet
+	peerSets [81]cellSet
+)
+
+func init() {
+	for i := 0; i < 9; i++ {
+		for j := 0; j < 9; j++ {
+			unitCells[i][j] = i*9 + j
+			unitCells[9+i][j] = j*9 + i
+			unitCells[18+i][j] = (i/3)*27 + (i%3)*3 + (j/3)*9 + j%3
+		}
+	}
+	for n := 0; n < 81; n++ {
+		for k, u := range UnitsOf(n) {
+			cellUnits[n][k] = u.id()
+		}
+		p := 0
+		for m := 0; m < 81; m++ {
+			if Sees(n, m) {
+				peers[n][p] = m
+				peerSets[n].add(m)
+				p++
+			}
+		}
+	}
+}
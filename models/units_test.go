@@ -0,0 +1,57 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitCells(t *testing.T) {
+	tt := []struct {
+		unit Unit
+		name string
+		want [9]int
+	}{
+		{Unit{Row, 0}, "row 1", [9]int{0, 1, 2, 3, 4, 5, 6, 7, 8}},
+		{Unit{Column, 8}, "column 9", [9]int{8, 17, 26, 35, 44, 53, 62, 71, 80}},
+		{Unit{Block, 4}, "block 5", [9]int{30, 31, 32, 39, 40, 41, 48, 49, 50}},
+	}
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, tc.unit.Cells())
+			assert.Equal(t, tc.name, tc.unit.String())
+			for _, c := range tc.want {
+				assert.Contains(t, UnitsOf(c), tc.unit)
+			}
+		})
+	}
+}
+
+func TestPeers(t *testing.T) {
+	for n := 0; n < 81; n++ {
+		seen := map[int]bool{}
+		for _, p := range peers[n] {
+			require.True(t, Sees(n, p))
+			seen[p] = true
+		}
+		require.Len(t, seen, 20)
+		require.False(t, seen[n])
+	}
+}
+
+func TestCellName(t *testing.T) {
+	assert.Equal(t, "r1c1", CellName(0))
+	assert.Equal(t, "r3c5", CellName(22))
+	assert.Equal(t, "r9c9", CellName(80))
+}
+
+func TestSees(t *testing.T) {
+	assert.True(t, Sees(0, 8), "same row")
+	assert.True(t, Sees(0, 72), "same column")
+	assert.True(t, Sees(0, 20), "same block")
+	assert.False(t, Sees(0, 0), "same square")
+	assert.False(t, Sees(0, 30), "no shared unit")
+}
@@ -0,0 +1,80 @@
+package models
+
+// A DuplicateDigit is a digit that is defined in more than one square of the
+// same unit.
+type DuplicateDigit struct {
+	Unit  Unit `json:"unit"`
+	Digit int  `json:"digit"`
+	// Cells are the squares defined as the digit, in order.
+	Cells []int `json:"cells"`
+}
+
+// A Validation lists every mistake found in a grid, so that they can all
+// be shown at once.
+type Validation struct {
+	Duplicates []DuplicateDigit `json:"duplicates,omitempty"`
+	// NoCandidates lists the undefined squares that have no possible value
+	// left, once the values defined in their row, column and block are
+	// excluded.
+	NoCandidates []int `json:"noCandidates,omitempty"`
+	// Wrong lists the defined squares whose values differ from the
+	// solution, if it is known.
+	Wrong []int `json:"wrong,omitempty"`
+}
+
+// Valid reports whether no mistakes were found.
+func (v Validation) Valid() bool {
+	return len(v.Duplicates) == 0 && len(v.NoCandidates) == 0 && len(v.Wrong) == 0
+}
+
+// Diverges reports whether some defined square differs from the solution.
+func (v Validation) Diverges() bool {
+	return len(v.Wrong) > 0
+}
+
+// Validate reports every digit that is defined more than once in a row,
+// column or block, and every undefined square with no possible value.
+// Unlike Normalize, it does not stop at the first mistake, and it does not
+// refine or solve the grid.
+func (g Grid) Validate() Validation {
+	var v Validation
+	for u := 0; u < 27; u++ {
+		var cells [10][]int
+		for _, c := range unitCells[u] {
+			if sq := g.squares[c]; sq.IsDefined() {
+				cells[sq.Value()] = append(cells[sq.Value()], c)
+			}
+		}
+		for d := 1; d <= 9; d++ {
+			if len(cells[d]) > 1 {
+				v.Duplicates = append(v.Duplicates, DuplicateDigit{unitByID(u), d, cells[d]})
+			}
+		}
+	}
+	for n, sq := range g.squares {
+		if sq.IsDefined() {
+			continue
+		}
+		for _, p := range peers[n] {
+			if other := g.squares[p]; other.IsDefined() {
+				sq &^= other
+			}
+		}
+		if sq == none {
+			v.NoCandidates = append(v.NoCandidates, n)
+		}
+	}
+	return v
+}
+
+// Differences returns the defined squares of g whose values differ from
+// the same squares of the given solution.
+func (g Grid) Differences(solution Grid) []int {
+	var diff []int
+	for n, sq := range g.squares {
+		if sq.IsDefined() && sq != solution.squares[n] {
+			diff = append(diff, n)
+		}
+	}
+	return diff
+}
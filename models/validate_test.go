@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	a := assert.New(t)
+
+	g := NewGrid([]byte(`
+		5.. ... ..5
+		.5. ... ...
+		... ... ...
+
+		... ... ...
+		... ... ...
+		... ... ...
+
+		... ... ...
+		123 456 78.
+		... ... ..9`))
+	v := g.Validate()
+	a.Equal([]DuplicateDigit{
+		{Unit{Row, 0}, 5, []int{0, 8}},
+		{Unit{Block, 0}, 5, []int{0, 10}},
+	}, v.Duplicates)
+	a.Equal([]int{71}, v.NoCandidates, "r8c9 has no candidates")
+	a.False(v.Valid())
+	a.False(v.Diverges())
+
+	// a valid grid has nothing to report, however few candidates it has
+	g = candidateGrid(map[int][]int{0: {1}, 1: {2, 3}})
+	a.True(g.Validate().Valid())
+	a.Equal(Validation{}, g.Validate())
+}
+
+func TestDifferences(t *testing.T) {
+	solution := NewGrid([]byte(casesNewGrid[0].in))
+	g := NewGrid([]byte("4356" + strings81('.')[4:]))
+	a := assert.New(t)
+	a.Equal([]int{3}, g.Differences(solution))
+	a.Empty(solution.Differences(solution))
+}
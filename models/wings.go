@@ -0,0 +1,299 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// A WingKind identifies one of the wing patterns.
+type WingKind int
+
+const (
+	// XYWing is a bivalue pivot {x,y} that sees two bivalue pincers {x,z}
+	// and {y,z}. One of the pincers must be z.
+	XYWing WingKind = iota
+	// XYZWing is a pivot {x,y,z} that sees two bivalue pincers {x,z} and
+	// {y,z}. One of the three squares must be z.
+	XYZWing
+	// WWing is two squares with the same two values {x,y} which do not
+	// see each other, joined by a strong link on x: a unit in which x can
+	// only go in two squares, one of which sees each of the pair.
+	// One of the pair must be y.
+	WWing
+	// WXYZWing is a pivot and three pincers that it sees, which between
+	// them hold exactly four values. All but one value (z) can only
+	// appear once among the four squares, so one of them must be z.
+	WXYZWing
+)
+
+// String implements the fmt.Stringer interface
+func (k WingKind) String() string {
+	switch k {
+	case XYWing:
+		return "XY-Wing"
+	case XYZWing:
+		return "XYZ-Wing"
+	case WWing:
+		return "W-Wing"
+	case WXYZWing:
+		return "WXYZ-Wing"
+	default:
+		return "Wing"
+	}
+}
+
+// A Wing describes one of the wing patterns, all of which prove that at
+// least one of the Pincers (or, for an XYZ-Wing or WXYZ-Wing, the Pivot)
+// holds Digit, so Digit can be removed from every square that sees all of
+// those candidates.
+type Wing struct {
+	Kind WingKind
+	// Pivot holds the pivot square, or for a W-Wing the two ends of
+	// the strong link.
+	Pivot   []int
+	Pincers []int
+	// Digit is the value that is eliminated.
+	Digit int
+	// Link is the value of the strong link in a W-Wing.
+	Link         int
+	Eliminations []Candidate
+}
+
+// Name returns the conventional name of the wing, e.g. "XY-Wing"
+func (w Wing) Name() string {
+	return w.Kind.String()
+}
+
+// String implements the fmt.Stringer interface
+func (w Wing) String() string {
+	var b strings.Builder
+	b.WriteString(w.Name())
+	b.WriteString(": ")
+	if w.Kind == WWing {
+		b.WriteString("strong link on ")
+		b.WriteString(strconv.Itoa(w.Link))
+		b.WriteString(" at ")
+	} else {
+		b.WriteString("pivot ")
+	}
+	b.WriteString(formatCells(w.Pivot))
+	b.WriteString(", pincers ")
+	b.WriteString(formatCells(w.Pincers))
+	b.WriteString(" => ")
+	b.WriteString(formatEliminations(w.Eliminations))
+	return b.String()
+}
+
+// findXYWing looks for an XY-Wing that allows at least one candidate
+// to be eliminated.
+func (g Grid) findXYWing() (Wing, bool) {
+	for p := 0; p < 81; p++ {
+		pivot := g.squares[p]
+		if pivot.Count() != 2 {
+			continue
+		}
+		pincers := g.peersWhere(p, func(sq Square) bool {
+			return sq.Count() == 2 && (sq&pivot).Count() == 1
+		})
+		var found Wing
+		forEachCombination(pincers, 2, func(ab []int) bool {
+			a, b := g.squares[ab[0]], g.squares[ab[1]]
+			z := a & b
+			if z.Count() != 1 || z&pivot != none || (a|b|pivot).Count() != 3 {
+				return true
+			}
+			found, _ = g.wing(XYWing, []int{p}, ab, z.Value(), ab)
+			return found.Pivot == nil
+		})
+		if found.Pivot != nil {
+			return found, true
+		}
+	}
+	return Wing{}, false
+}
+
+// findXYZWing looks for an XYZ-Wing that allows at least one candidate
+// to be eliminated.
+func (g Grid) findXYZWing() (Wing, bool) {
+	for p := 0; p < 81; p++ {
+		pivot := g.squares[p]
+		if pivot.Count() != 3 {
+			continue
+		}
+		pincers := g.peersWhere(p, func(sq Square) bool {
+			return sq.Count() == 2 && sq&pivot == sq
+		})
+		var found Wing
+		forEachCombination(pincers, 2, func(ab []int) bool {
+			z := g.squares[ab[0]] & g.squares[ab[1]]
+			if z.Count() != 1 {
+				return true
+			}
+			found, _ = g.wing(XYZWing, []int{p}, ab, z.Value(), []int{p, ab[0], ab[1]})
+			return found.Pivot == nil
+		})
+		if found.Pivot != nil {
+			return found, true
+		}
+	}
+	return Wing{}, false
+}
+
+// findWWing looks for a W-Wing that allows at least one candidate
+// to be eliminated.
+func (g Grid) findWWing() (Wing, bool) {
+	for a := 0; a < 81; a++ {
+		pair := g.squares[a]
+		if pair.Count() != 2 {
+			continue
+		}
+		for b := a + 1; b < 81; b++ {
+			if g.squares[b] != pair || Sees(a, b) {
+				continue
+			}
+			for _, x := range pair.Values() {
+				y := (pair &^ squareEnum[x]).Value()
+				for u := 0; u < 27; u++ {
+					link := g.positions(u, x)
+					if len(link) != 2 {
+						continue
+					}
+					for _, ends := range [2][2]int{{link[0], link[1]}, {link[1], link[0]}} {
+						if ends[0] == a || ends[1] == b || ends[0] == b || ends[1] == a {
+							continue
+						}
+						if !Sees(ends[0], a) || !Sees(ends[1], b) {
+							continue
+						}
+						w, ok := g.wing(WWing, ends[:], []int{a, b}, y, []int{a, b})
+						if ok {
+							w.Link = x
+							return w, true
+						}
+					}
+				}
+			}
+		}
+	}
+	return Wing{}, false
+}
+
+// findWXYZWing looks for a WXYZ-Wing that allows at least one candidate
+// to be eliminated.
+func (g Grid) findWXYZWing() (Wing, bool) {
+	for p := 0; p < 81; p++ {
+		pivot := g.squares[p]
+		if n := pivot.Count(); n < 2 || n > 4 {
+			continue
+		}
+		pincers := g.peersWhere(p, func(sq Square) bool {
+			return (sq | pivot).Count() <= 4
+		})
+		var found Wing
+		forEachCombination(pincers, 3, func(abc []int) bool {
+			cells := []int{p, abc[0], abc[1], abc[2]}
+			union := none
+			for _, c := range cells {
+				union |= g.squares[c]
+			}
+			if union.Count() != 4 {
+				return true
+			}
+			z := 0
+			for _, d := range union.Values() {
+				if !g.restricted(cells, d) {
+					if z != 0 {
+						return true
+					}
+					z = d
+				}
+			}
+			if z == 0 {
+				return true
+			}
+			var zs []int
+			for _, c := range cells {
+				if g.squares[c].Has(z) {
+					zs = append(zs, c)
+				}
+			}
+			found, _ = g.wing(WXYZWing, []int{p}, abc, z, zs)
+			return found.Pivot == nil
+		})
+		if found.Pivot != nil {
+			return found, true
+		}
+	}
+	return Wing{}, false
+}
+
+// wing builds a wing that eliminates digit z from every square which sees
+// all of the given targets. Returns false if there is nothing to eliminate.
+func (g Grid) wing(kind WingKind, pivot, pincers []int, z int, targets []int) (Wing, bool) {
+	elims := g.eliminationsSeeing(z, targets)
+	if len(elims) == 0 {
+		return Wing{}, false
+	}
+	return Wing{
+		Kind:         kind,
+		Pivot:        append([]int(nil), pivot...),
+		Pincers:      append([]int(nil), pincers...),
+		Digit:        z,
+		Eliminations: elims,
+	}, true
+}
+
+// eliminationsSeeing returns the candidates for digit d in every undefined
+// square that sees all of the given cells.
+func (g Grid) eliminationsSeeing(d int, cells []int) []Candidate {
+	var elims []Candidate
+	for _, c := range peers[cells[0]] {
+		sq := g.squares[c]
+		if !sq.Has(d) || sq.IsDefined() {
+			continue
+		}
+		if seesAll(c, cells) {
+			elims = append(elims, Candidate{c, d})
+		}
+	}
+	return elims
+}
+
+// peersWhere returns the undefined peers of square n which match the
+// given filter.
+func (g Grid) peersWhere(n int, match func(Square) bool) []int {
+	var out []int
+	for _, c := range peers[n] {
+		sq := g.squares[c]
+		if !sq.IsDefined() && sq != none && match(sq) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// restricted reports whether every pair of the given cells that could
+// hold d see each other, so that d can appear at most once among them.
+func (g Grid) restricted(cells []int, d int) bool {
+	for i, a := range cells {
+		if !g.squares[a].Has(d) {
+			continue
+		}
+		for _, b := range cells[i+1:] {
+			if g.squares[b].Has(d) && !Sees(a, b) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// seesAll reports whether square n sees every one of the given cells.
+func seesAll(n int, cells []int) bool {
+	for _, c := range cells {
+		if !Sees(n, c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindWings(t *testing.T) {
+	// in the W-Wing, 1 can only go in r1c9 or r5c9 within column 9
+	wwing := map[int][]int{0: {1, 2}, 40: {1, 2}}
+	for r := 0; r < 9; r++ {
+		if r != 0 && r != 4 {
+			wwing[r*9+8] = without(1)
+		}
+	}
+
+	tt := []struct {
+		name string
+		grid Grid
+		find func(Grid) (Wing, bool)
+		want Wing
+		desc string
+	}{
+		{
+			name: "xy-wing",
+			grid: candidateGrid(map[int][]int{0: {1, 2}, 4: {1, 3}, 36: {2, 3}}),
+			find: Grid.findXYWing,
+			want: Wing{
+				Kind:         XYWing,
+				Pivot:        []int{0},
+				Pincers:      []int{4, 36},
+				Digit:        3,
+				Eliminations: []Candidate{{40, 3}},
+			},
+			desc: "XY-Wing: pivot r1c1, pincers r1c5,r5c1 => r5c5<>3",
+		},
+		{
+			name: "xyz-wing",
+			grid: candidateGrid(map[int][]int{0: {1, 2, 3}, 4: {1, 3}, 10: {2, 3}}),
+			find: Grid.findXYZWing,
+			want: Wing{
+				Kind:         XYZWing,
+				Pivot:        []int{0},
+				Pincers:      []int{4, 10},
+				Digit:        3,
+				Eliminations: []Candidate{{1, 3}, {2, 3}},
+			},
+			desc: "XYZ-Wing: pivot r1c1, pincers r1c5,r2c2 => r1c2<>3, r1c3<>3",
+		},
+		{
+			name: "w-wing",
+			grid: candidateGrid(wwing),
+			find: Grid.findWWing,
+			want: Wing{
+				Kind:         WWing,
+				Pivot:        []int{8, 44},
+				Pincers:      []int{0, 40},
+				Digit:        2,
+				Link:         1,
+				Eliminations: []Candidate{{4, 2}, {36, 2}},
+			},
+			desc: "W-Wing: strong link on 1 at r1c9,r5c9, pincers r1c1,r5c5 => r1c5<>2, r5c1<>2",
+		},
+		{
+			name: "wxyz-wing",
+			grid: candidateGrid(map[int][]int{
+				0: {1, 2, 3}, 1: {1, 4}, 10: {2, 4}, 36: {3, 4},
+			}),
+			find: Grid.findWXYZWing,
+			want: Wing{
+				Kind:         WXYZWing,
+				Pivot:        []int{0},
+				Pincers:      []int{1, 10, 36},
+				Digit:        4,
+				Eliminations: []Candidate{{9, 4}, {18, 4}, {28, 4}, {37, 4}, {46, 4}},
+			},
+			desc: "WXYZ-Wing: pivot r1c1, pincers r1c2,r2c2,r5c1 => r2c1<>4, r3c1<>4, r4c2<>4, r5c2<>4, r6c2<>4",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := tc.find(tc.grid)
+			require.True(t, ok)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.desc, got.String())
+		})
+	}
+}
+
+func TestWingStep(t *testing.T) {
+	a := assert.New(t)
+
+	g := candidateGrid(map[int][]int{0: {1, 2, 3}, 4: {1, 3}, 10: {2, 3}})
+	step, ok := BuiltinTechniques(PassXYZWing)[0].Apply(&g)
+	require.True(t, ok)
+	a.Equal([]int{0}, step.Pivot)
+	a.Equal([]int{4, 10}, step.Pincers)
+	a.Zero(step.Digit)
+
+	wwing := map[int][]int{0: {1, 2}, 40: {1, 2}}
+	for r := 1; r < 9; r++ {
+		if r != 4 {
+			wwing[r*9+8] = without(1)
+		}
+	}
+	g = candidateGrid(wwing)
+	step, ok = BuiltinTechniques(PassWWing)[0].Apply(&g)
+	require.True(t, ok)
+	a.Equal([]int{8, 44}, step.Pivot, "the ends of the strong link")
+	a.Equal([]int{0, 40}, step.Pincers)
+	a.Equal(1, step.Digit, "the value of the strong link")
+}
+
+func TestFindWingsNone(t *testing.T) {
+	// the pincers do not share a value
+	g := candidateGrid(map[int][]int{0: {1, 2}, 4: {1, 3}, 36: {2, 4}})
+	for _, find := range []func(Grid) (Wing, bool){
+		Grid.findXYWing, Grid.findXYZWing, Grid.findWWing, Grid.findWXYZWing,
+	} {
+		_, ok := find(g)
+		assert.False(t, ok)
+	}
+}
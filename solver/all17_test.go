@@ -0,0 +1,86 @@
+//go:build slow
+// +build slow
+
+package solver
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"mcconachie.co/sudoku/models"
+)
+
+// loadAll17 reads every puzzle from the 17 clue collection.
+func loadAll17() []models.Grid {
+	infile := "../all_17_clue_sudokus.txt"
+	f, err := os.Open(infile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	s.Scan() // discard count of puzzles
+
+	grids := make([]models.Grid, 0, 49151)
+	for s.Scan() {
+		grids = append(grids, models.NewGrid(s.Bytes()))
+	}
+
+	if err := s.Err(); err != nil {
+		log.Fatal(fmt.Errorf("error reading input: %w", err))
+	}
+	return grids
+}
+
+func TestAll17(t *testing.T) {
+	for _, engine := range []Engine{Backtracking, DancingLinks} {
+		solv := Solver{Engine: engine}
+		for i, sudoku := range loadAll17() {
+			if _, err := solv.Solve(sudoku); err != nil {
+				t.Errorf("%s: puzzle %d: %v", engine, i+1, err)
+			}
+		}
+	}
+}
+
+func TestAll17AreMinimal(t *testing.T) {
+	solv := Solver{Engine: DancingLinks}
+	for i, sudoku := range loadAll17() {
+		ok, err := solv.IsMinimal(sudoku)
+		if err != nil || !ok {
+			t.Errorf("puzzle %d: minimal: %v, %v", i+1, ok, err)
+		}
+	}
+}
+
+func BenchmarkAll17(b *testing.B) {
+	grids := loadAll17()
+	solvers := []struct {
+		name string
+		solv Solver
+	}{
+		{"backtracking", Solver{Engine: Backtracking}},
+		{"backtracking with subsets", Solver{
+			Engine: Backtracking,
+			Passes: models.BasicPasses | models.SubsetPasses,
+		}},
+		{"dancing links", Solver{Engine: DancingLinks}},
+	}
+	for _, tc := range solvers {
+		solv := tc.solv
+		b.Run(tc.name, func(b *testing.B) {
+			backtracks := 0
+			for n := 0; n < b.N; n++ {
+				for _, g := range grids {
+					res, _ := solv.Solve(g)
+					backtracks += res.Backtracks
+				}
+			}
+			b.ReportMetric(float64(backtracks)/float64(b.N), "backtracks/op")
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package solver
+
+import "mcconachie.co/sudoku/models"
+
+// DefaultLimit is the number of solutions after which CountSolutions stops
+// searching, when the Solver does not specify a Limit of its own.
+// Two is enough to tell a proper sudoku from an ambiguous one.
+const DefaultLimit = 2
+
+// Solutions describes the solutions of a grid, as found by CountSolutions.
+type Solutions struct {
+	// Count is the number of distinct solutions found.
+	Count int
+	// Capped is true if the search reached the limit with branches left
+	// unexplored, in which case Count is a lower bound (e.g. "2 or more").
+	// A search that reaches the limit on its last branch is not capped.
+	Capped bool
+	// Grids holds the first (up to) two solutions found.
+	Grids []models.Grid
+}
+
+// Unique reports whether the grid has exactly one solution.
+func (s Solutions) Unique() bool {
+	return s.Count == 1 && !s.Capped
+}
+
+// Diff returns the indices of the squares that differ between the first
+// two solutions, or nil if fewer than two solutions were found.
+func (s Solutions) Diff() []int {
+	if len(s.Grids) < 2 {
+		return nil
+	}
+	a, b := s.Grids[0], s.Grids[1]
+	diff := make([]int, 0, 4)
+	for i := 0; i < 81; i++ {
+		if a.Get(i) != b.Get(i) {
+			diff = append(diff, i)
+		}
+	}
+	return diff
+}
+
+// CountSolutions counts the solutions of the given grid, stopping once
+// the solver's Limit (or DefaultLimit) has been reached.
+// The given grid is not modified.
+func (s *Solver) CountSolutions(g models.Grid) Solutions {
+	limit := s.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if s.Engine == DancingLinks {
+		return s.countDLX(g, limit)
+	}
+	// the uniqueness passes assume the very thing that is being counted
+	c := counter{
+		techniques: models.BuiltinTechniquesWith(s.passes()&^models.UniquenessPasses, s.Limits),
+		limit:      limit,
+	}
+	c.count(g.Clone())
+	return Solutions{
+		Count:  c.found,
+		Capped: c.capped,
+		Grids:  c.grids,
+	}
+}
+
+// counter holds the state for a single call to CountSolutions.
+type counter struct {
+	techniques []models.Technique
+	limit      int
+	found      int
+	grids      []models.Grid
+	// capped is set if the search stopped with branches left to explore.
+	capped bool
+}
+
+// count recursively explores every solution of g, returning false once
+// the limit has been reached and the search should stop.
+func (c *counter) count(g *models.Grid) bool {
+	if err := g.Propagate(c.techniques); err != nil {
+		return true
+	}
+	ix, done := findNextEmptyCell(g)
+	if done {
+		if len(c.grids) < 2 {
+			c.grids = append(c.grids, *g.Clone())
+		}
+		c.found++
+		return c.found < c.limit
+	}
+
+	for k := 1; k <= 9; k++ {
+		if !g.CanSet(ix, k) {
+			continue
+		}
+		next := g.Clone()
+		next.Set(ix, k)
+		if !c.count(next) {
+			for k++; k <= 9; k++ {
+				if g.CanSet(ix, k) {
+					c.capped = true
+				}
+			}
+			return false
+		}
+	}
+
+	return true
+}
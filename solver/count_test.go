@@ -0,0 +1,140 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"mcconachie.co/sudoku/models"
+)
+
+var casesCount = []struct {
+	name   string
+	limit  int
+	in     string
+	count  int
+	capped bool
+	diff   []int
+}{
+	{
+		name:  "proper sudoku",
+		in:    cases_solve[3].in,
+		count: 1,
+	},
+	{
+		name: "no solution",
+		in: `
+			123 456 78.
+			... ... ...
+			... ... ...
+
+			... ... ..9
+			... ... ...
+			... ... ...
+
+			... ... ...
+			... ... ...
+			... ... ...`,
+		count: 0,
+	},
+	{
+		name: "two solutions (deadly rectangle)",
+		in: `
+			43. .69 781
+			68. .71 493
+			197 834 562
+
+			826 195 347
+			374 682 915
+			951 743 628
+
+			519 326 874
+			248 957 136
+			763 418 259`,
+		// both values of the rectangle are tried, so the count is exact
+		count: 2,
+		diff:  []int{2, 3, 11, 12},
+	},
+	{
+		name:  "two solutions with a limit of one",
+		limit: 1,
+		in: `
+			43. .69 781
+			68. .71 493
+			197 834 562
+
+			826 195 347
+			374 682 915
+			951 743 628
+
+			519 326 874
+			248 957 136
+			763 418 259`,
+		count:  1,
+		capped: true,
+	},
+	{
+		name:  "one solution with a limit of one",
+		limit: 1,
+		in: `
+			432 .69 781
+			68. .71 493
+			197 834 562
+
+			826 195 347
+			374 682 915
+			951 743 628
+
+			519 326 874
+			248 957 136
+			763 418 259`,
+		count: 1,
+	},
+	{
+		name:  "two solutions with a higher limit",
+		limit: 5,
+		in: `
+			43. .69 781
+			68. .71 493
+			197 834 562
+
+			826 195 347
+			374 682 915
+			951 743 628
+
+			519 326 874
+			248 957 136
+			763 418 259`,
+		count: 2,
+		diff:  []int{2, 3, 11, 12},
+	},
+	{
+		name:   "empty grid",
+		in:     strings.Repeat(".", 81),
+		count:  2,
+		capped: true,
+	},
+}
+
+func TestCountSolutions(t *testing.T) {
+	for _, engine := range []Engine{Backtracking, DancingLinks} {
+		for _, tc := range casesCount {
+			tc, engine := tc, engine
+			t.Run(engine.String()+"/"+tc.name, func(t *testing.T) {
+				t.Parallel()
+				s := Solver{Engine: engine, Limit: tc.limit}
+				got := s.CountSolutions(models.NewGrid([]byte(tc.in)))
+				assert.Equal(t, tc.count, got.Count)
+				assert.Equal(t, tc.capped, got.Capped)
+				assert.Equal(t, tc.count == 1 && !tc.capped, got.Unique())
+				assert.Len(t, got.Grids, tc.count)
+				if tc.diff != nil {
+					assert.Equal(t, tc.diff, got.Diff())
+				}
+				for _, g := range got.Grids {
+					assert.NotContains(t, g.String(), ".")
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package solver
+
+import "mcconachie.co/sudoku/models"
+
+// The exact cover matrix for a sudoku has one row for each of the 729
+// possible (cell, digit) placements, and one column for each of the 324
+// constraints that a solution must satisfy exactly once:
+//
+//	  0-80   each cell holds a digit
+//	 81-161  each row holds each digit
+//	162-242  each column holds each digit
+//	243-323  each block holds each digit
+const (
+	dlxColumns = 324
+	dlxRows    = 729
+)
+
+// dlx is an array based implementation of Knuth's Dancing Links.
+// Node 0 is the root, nodes 1 to dlxColumns are the column headers,
+// and the remaining nodes belong to the rows of the matrix.
+type dlx struct {
+	left, right, up, down, col, row []int
+	size                            [dlxColumns + 1]int
+
+	solution []int
+	nodes    int
+	// backtracks counts the rows that were chosen and later undone.
+	backtracks int
+	// stopped is set if the search was stopped with rows left to try.
+	stopped bool
+}
+
+// newDLX builds the exact cover matrix for the given grid, including only
+// the placements that are still candidates, and selects the rows for the
+// squares that are already defined.
+// Returns false if the defined squares conflict with each other.
+func newDLX(g models.Grid) (*dlx, bool) {
+	n := 1 + dlxColumns + 4*dlxRows
+	x := &dlx{
+		left:     make([]int, 1+dlxColumns, n),
+		right:    make([]int, 1+dlxColumns, n),
+		up:       make([]int, 1+dlxColumns, n),
+		down:     make([]int, 1+dlxColumns, n),
+		col:      make([]int, 1+dlxColumns, n),
+		row:      make([]int, 1+dlxColumns, n),
+		solution: make([]int, 0, 81),
+	}
+	for i := 0; i <= dlxColumns; i++ {
+		x.left[i] = i - 1
+		x.right[i] = i + 1
+		x.up[i] = i
+		x.down[i] = i
+		x.col[i] = i
+		x.row[i] = -1
+	}
+	x.left[0] = dlxColumns
+	x.right[dlxColumns] = 0
+
+	// rowNode remembers the first node of each matrix row
+	var rowNode [dlxRows]int
+	for cell := 0; cell < 81; cell++ {
+		sq := g.Get(cell)
+		for _, d := range sq.Values() {
+			rowNode[cell*9+d-1] = x.addRow(cell, d)
+		}
+	}
+
+	var covered [dlxColumns + 1]bool
+	for cell := 0; cell < 81; cell++ {
+		sq := g.Get(cell)
+		if !sq.IsDefined() {
+			continue
+		}
+		r := rowNode[cell*9+sq.Values()[0]-1]
+		j := r
+		for {
+			c := x.col[j]
+			if covered[c] {
+				return nil, false
+			}
+			covered[c] = true
+			x.cover(c)
+			j = x.right[j]
+			if j == r {
+				break
+			}
+		}
+		x.solution = append(x.solution, x.row[r])
+	}
+
+	return x, true
+}
+
+// addRow appends the matrix row for placing digit d in the given cell,
+// returning the index of its first node.
+func (x *dlx) addRow(cell, d int) int {
+	r, c, b := cell/9, cell%9, (cell/27)*3+(cell%9)/3
+	cols := [4]int{
+		cell,
+		81 + r*9 + d - 1,
+		162 + c*9 + d - 1,
+		243 + b*9 + d - 1,
+	}
+
+	first := len(x.col)
+	for i, c := range cols {
+		c++ // skip the root node
+		j := len(x.col)
+		x.col = append(x.col, c)
+		x.row = append(x.row, cell*9+d-1)
+		x.up = append(x.up, x.up[c])
+		x.down = append(x.down, c)
+		x.down[x.up[c]] = j
+		x.up[c] = j
+		x.size[c]++
+
+		x.left = append(x.left, first+(i+3)%4)
+		x.right = append(x.right, first+(i+1)%4)
+	}
+	return first
+}
+
+// cover removes column c from the header list, and removes every row
+// that intersects c from the other columns.
+func (x *dlx) cover(c int) {
+	x.right[x.left[c]] = x.right[c]
+	x.left[x.right[c]] = x.left[c]
+	for i := x.down[c]; i != c; i = x.down[i] {
+		for j := x.right[i]; j != i; j = x.right[j] {
+			x.down[x.up[j]] = x.down[j]
+			x.up[x.down[j]] = x.up[j]
+			x.size[x.col[j]]--
+		}
+	}
+}
+
+// uncover reverses the effect of cover(c).
+func (x *dlx) uncover(c int) {
+	for i := x.up[c]; i != c; i = x.up[i] {
+		for j := x.left[i]; j != i; j = x.left[j] {
+			x.size[x.col[j]]++
+			x.down[x.up[j]] = j
+			x.up[x.down[j]] = j
+		}
+	}
+	x.right[x.left[c]] = c
+	x.left[x.right[c]] = c
+}
+
+// search runs Algorithm X, calling found with each solution.
+// The search stops as soon as found returns false, in which case
+// search also returns false.
+func (x *dlx) search(found func(rows []int) bool) bool {
+	x.nodes++
+	if x.right[0] == 0 {
+		return found(x.solution)
+	}
+
+	// choose the column with the fewest rows
+	c, best := 0, dlxRows+1
+	for j := x.right[0]; j != 0; j = x.right[j] {
+		if x.size[j] < best {
+			c, best = j, x.size[j]
+		}
+	}
+	if best == 0 {
+		return true
+	}
+
+	x.cover(c)
+	for r := x.down[c]; r != c; r = x.down[r] {
+		x.solution = append(x.solution, x.row[r])
+		for j := x.right[r]; j != r; j = x.right[j] {
+			x.cover(x.col[j])
+		}
+
+		more := x.search(found)
+
+		for j := x.left[r]; j != r; j = x.left[j] {
+			x.uncover(x.col[j])
+		}
+		x.solution = x.solution[:len(x.solution)-1]
+		if !more {
+			x.stopped = x.stopped || x.down[r] != c
+			x.uncover(c)
+			return false
+		}
+		x.backtracks++
+	}
+	x.uncover(c)
+	return true
+}
+
+// gridFromRows builds a solved grid from the rows of an exact cover.
+func gridFromRows(rows []int) models.Grid {
+	g := models.NewGrid(nil)
+	for _, r := range rows {
+		g.Set(r/9, r%9+1)
+	}
+	return g
+}
+
+// solveDLX finds the first solution for g using Dancing Links.
+func (s *Solver) solveDLX(g models.Grid) (models.Grid, int, int, bool) {
+	x, ok := newDLX(g)
+	if !ok {
+		return models.Grid{}, 0, 0, false
+	}
+	var solved models.Grid
+	done := !x.search(func(rows []int) bool {
+		solved = gridFromRows(rows)
+		return false
+	})
+	return solved, x.backtracks, x.nodes, done
+}
+
+// countDLX counts the solutions of g using Dancing Links, stopping once
+// limit solutions have been found.
+func (s *Solver) countDLX(g models.Grid, limit int) Solutions {
+	var sols Solutions
+	x, ok := newDLX(g)
+	if !ok {
+		return sols
+	}
+	x.search(func(rows []int) bool {
+		if len(sols.Grids) < 2 {
+			sols.Grids = append(sols.Grids, gridFromRows(rows))
+		}
+		sols.Count++
+		return sols.Count < limit
+	})
+	sols.Capped = x.stopped
+	return sols
+}
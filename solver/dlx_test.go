@@ -0,0 +1,45 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"mcconachie.co/sudoku/models"
+)
+
+func TestNewDLXConflictingSquares(t *testing.T) {
+	grid := models.NewGrid([]byte(`
+		5.. ... ..5
+		... ... ...
+		... ... ...
+
+		... ... ...
+		... ... ...
+		... ... ...
+
+		... ... ...
+		... ... ...
+		... ... ...`))
+	_, ok := newDLX(grid)
+	require.False(t, ok)
+
+	s := Solver{Engine: DancingLinks}
+	require.Equal(t, 0, s.CountSolutions(grid).Count)
+}
+
+func TestDLXRestoresMatrix(t *testing.T) {
+	x, ok := newDLX(models.NewGrid([]byte(cases_solve[3].in)))
+	require.True(t, ok)
+	before := append([]int(nil), x.down...)
+	sizes := x.size
+
+	solutions := 0
+	x.search(func([]int) bool {
+		solutions++
+		return true
+	})
+
+	require.Equal(t, 1, solutions)
+	require.Equal(t, before, x.down)
+	require.Equal(t, sizes, x.size)
+}
@@ -0,0 +1,183 @@
+package solver
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"mcconachie.co/sudoku/models"
+)
+
+// ErrSolved is returned by Hint when every square is already defined.
+var ErrSolved = errors.New("the grid is already solved")
+
+// A Hint is the next logical step for a partially solved grid.
+type Hint struct {
+	Step models.Step `json:"step"`
+	// Explanation describes the step in plain words.
+	Explanation string `json:"explanation"`
+	// Highlight holds the squares that make up the pattern, followed by
+	// any other squares that the step changes.
+	Highlight []int `json:"highlight"`
+}
+
+// Hint finds the easiest next step for the given grid, using the same
+// techniques as SolveSteps, without solving the rest of it. It never
+// guesses, and it leaves out the forcing chains and nets unless
+// ForcingHints is set. The given grid is not modified.
+//
+// Returns ErrSolved if the grid is already solved, ErrStuck if none of the
+// techniques applies, or an error if the grid is invalid.
+func (s *Solver) Hint(g models.Grid) (Hint, error) {
+	if isSolved(g) {
+		if err := g.Check(); err != nil {
+			return Hint{}, err
+		}
+		return Hint{}, ErrSolved
+	}
+	grid := g.Clone()
+	if err := grid.Apply(givens(*grid)); err != nil {
+		return Hint{}, err
+	}
+	t, step, ok := nextStep(grid, s.hintTechniques())
+	if !ok {
+		return Hint{}, ErrStuck
+	}
+	if err := grid.Check(); err != nil {
+		return Hint{}, models.DetectedBy(err, t, step)
+	}
+	return Hint{
+		Step:        step,
+		Explanation: explain(step),
+		Highlight:   highlight(step),
+	}, nil
+}
+
+// hintTechniques returns the techniques for Hint.
+func (s *Solver) hintTechniques() []models.Technique {
+	ts := s.techniques()
+	if s.ForcingHints {
+		return ts
+	}
+	hints := ts[:0]
+	for _, t := range ts {
+		if models.PassOf(t)&models.ForcingPasses == 0 {
+			hints = append(hints, t)
+		}
+	}
+	return hints
+}
+
+// explain describes a step in plain words: the pattern, as given in the
+// step's description, what it shows, and the proof of a forcing chain.
+func explain(s models.Step) string {
+	switch {
+	case s.Technique == "Naked Single" && len(s.Placements) == 1:
+		c := s.Placements[0]
+		return models.CellName(c.Cell) + " must be " + strconv.Itoa(c.Digit) +
+			", since every other value is already in its row, column or block."
+	case s.Technique == "Hidden Single" && len(s.Placements) == 1 && len(s.Units) == 1:
+		c := s.Placements[0]
+		return models.CellName(c.Cell) + " must be " + strconv.Itoa(c.Digit) +
+			", since it is the only square in " + s.Units[0].String() +
+			" that can hold " + article(strconv.Itoa(c.Digit)) + " " + strconv.Itoa(c.Digit) + "."
+	}
+
+	var b strings.Builder
+	b.WriteString("Look for ")
+	b.WriteString(article(s.Technique))
+	b.WriteString(" ")
+	b.WriteString(s.Technique)
+	if detail := patternOf(s); detail != "" {
+		b.WriteString(": ")
+		b.WriteString(detail)
+	} else if len(s.Cells) > 0 {
+		names := make([]string, len(s.Cells))
+		for i, c := range s.Cells {
+			names[i] = models.CellName(c)
+		}
+		b.WriteString(" at ")
+		b.WriteString(strings.Join(names, ", "))
+	}
+	b.WriteString(".")
+
+	var results []string
+	for _, c := range s.Placements {
+		results = append(results, inWords(models.Inference{Candidate: c, Placed: true}))
+	}
+	for _, c := range s.Eliminations {
+		results = append(results, inWords(models.Inference{Candidate: c}))
+	}
+	if len(results) > 0 {
+		b.WriteString(" It shows that ")
+		b.WriteString(strings.Join(results, ", "))
+		b.WriteString(".")
+	}
+	for _, br := range s.Proof {
+		b.WriteString(" If ")
+		b.WriteString(inWords(models.Inference{Candidate: br.Assumption, Placed: true}))
+		b.WriteString(", then ")
+		steps := make([]string, len(br.Steps))
+		for i, inf := range br.Steps {
+			steps[i] = inWords(inf)
+		}
+		b.WriteString(strings.Join(steps, ", "))
+		b.WriteString(".")
+	}
+	return b.String()
+}
+
+// patternOf returns the part of the step's description that describes the
+// pattern, e.g. "pivot r1c1, pincers r1c5,r5c1" from
+// "XY-Wing: pivot r1c1, pincers r1c5,r5c1 => r5c5<>3".
+// Returns "" if the description does not start with the technique.
+func patternOf(s models.Step) string {
+	if !strings.HasPrefix(s.Description, s.Technique) {
+		return ""
+	}
+	detail := strings.TrimPrefix(s.Description, s.Technique)
+	detail = strings.TrimPrefix(strings.TrimPrefix(detail, ":"), " ")
+	if i := strings.Index(detail, " => "); i >= 0 {
+		detail = detail[:i]
+	}
+	return detail
+}
+
+// article returns "a" or "an" to go before the name of a technique, going
+// by how the name is read out: "an X-Wing", "an ALS-XZ" and "an Empty
+// Rectangle", but "a Unique Rectangle". It works for digits, too.
+func article(name string) string {
+	if name != "" && strings.IndexByte("AEIOX8", name[0]) >= 0 {
+		return "an"
+	}
+	return "a"
+}
+
+// inWords describes an inference in plain words, e.g. "r1c2 must be 5"
+func inWords(inf models.Inference) string {
+	if inf.Placed {
+		return models.CellName(inf.Cell) + " must be " + strconv.Itoa(inf.Digit)
+	}
+	return models.CellName(inf.Cell) + " cannot be " + strconv.Itoa(inf.Digit)
+}
+
+// highlight returns the squares of the step's pattern, followed by any
+// other squares that it changes.
+func highlight(s models.Step) []int {
+	cells := append([]int(nil), s.Cells...)
+	add := func(c int) {
+		for _, x := range cells {
+			if x == c {
+				return
+			}
+		}
+		cells = append(cells, c)
+	}
+	for _, c := range s.Placements {
+		add(c.Cell)
+	}
+	for _, c := range s.Eliminations {
+		add(c.Cell)
+	}
+	return cells
+}
@@ -0,0 +1,200 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcconachie.co/sudoku/models"
+)
+
+func TestHint(t *testing.T) {
+	var s Solver
+	puzzle := loadPuzzles(t, 1)[0]
+	before := puzzle.String()
+
+	h, err := s.Hint(puzzle)
+	require.NoError(t, err)
+	assert.Equal(t, "Hidden Single", h.Step.Technique)
+	assert.Equal(t, []models.Candidate{{Cell: 44, Digit: 1}}, h.Step.Placements)
+	assert.Equal(t, "r5c9 must be 1, since it is the only square in block 6 that can hold a 1.", h.Explanation)
+	assert.Equal(t, []int{44}, h.Highlight)
+	assert.Equal(t, before, puzzle.String())
+
+	// the hint is the first step of the full solve
+	log, err := s.SolveSteps(puzzle)
+	require.NoError(t, err)
+	assert.Equal(t, log.Steps[0], h.Step)
+}
+
+func TestHintAdvanced(t *testing.T) {
+	var s Solver
+	puzzle := loadPuzzles(t, 9)[8].AssumeUnique()
+
+	// fill in every value that the singles can find, as a player might
+	basic := models.NewRegistry()
+	for _, x := range models.BuiltinTechniques(models.BasicPasses) {
+		require.NoError(t, basic.Register(x))
+	}
+	stuck := Solver{Registry: basic}
+	log, err := stuck.SolveSteps(puzzle)
+	require.ErrorIs(t, err, ErrStuck)
+	g := models.NewGrid([]byte(log.Puzzle))
+	require.NoError(t, log.Replay(g))
+	player := models.NewGrid([]byte(puzzleString(g))).AssumeUnique()
+
+	_, err = stuck.Hint(player)
+	assert.ErrorIs(t, err, ErrStuck)
+
+	h, err := s.Hint(player)
+	require.NoError(t, err)
+	t.Log(h.Explanation)
+	assert.Empty(t, h.Step.Placements)
+	assert.NotEmpty(t, h.Step.Eliminations)
+	assert.Contains(t, h.Explanation, "Look for a "+h.Step.Technique)
+	for _, c := range h.Step.Eliminations {
+		assert.Contains(t, h.Highlight, c.Cell)
+	}
+}
+
+func TestHintForcing(t *testing.T) {
+	// the 976th puzzle needs a forcing chain or net
+	puzzle := loadPuzzles(t, 976)[975].AssumeUnique()
+
+	logical := models.NewRegistry()
+	for _, x := range models.BuiltinTechniques(models.AllPasses &^ models.ForcingPasses) {
+		require.NoError(t, logical.Register(x))
+	}
+	stuck := Solver{Registry: logical}
+	log, err := stuck.SolveSteps(puzzle)
+	require.ErrorIs(t, err, ErrStuck)
+	g := models.NewGrid([]byte(log.Puzzle))
+	require.NoError(t, log.Replay(g))
+	// the replayed grid keeps the candidates that the other techniques
+	// eliminated
+	player := g.AssumeUnique()
+
+	var s Solver
+	_, err = s.Hint(player)
+	assert.ErrorIs(t, err, ErrStuck, "forcing is left out of hints")
+
+	s.ForcingHints = true
+	h, err := s.Hint(player)
+	require.NoError(t, err)
+	assert.Contains(t, h.Step.Technique, "Forcing")
+}
+
+func TestHintErrors(t *testing.T) {
+	var s Solver
+
+	_, err := s.Hint(models.NewGrid([]byte(cases_solve[0].in)))
+	assert.ErrorIs(t, err, ErrSolved)
+
+	_, err = s.Hint(models.NewGrid([]byte("55" + strings.Repeat(".", 79))))
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrStuck)
+	assert.NotErrorIs(t, err, ErrSolved)
+}
+
+func TestExplain(t *testing.T) {
+	tt := []struct {
+		name string
+		step models.Step
+		want string
+	}{
+		{
+			name: "naked single",
+			step: models.Step{
+				Technique:  "Naked Single",
+				Placements: []models.Candidate{{Cell: 40, Digit: 7}},
+			},
+			want: "r5c5 must be 7, since every other value is already in its row, column or block.",
+		},
+		{
+			name: "hidden single",
+			step: models.Step{
+				Technique:  "Hidden Single",
+				Placements: []models.Candidate{{Cell: 0, Digit: 8}},
+				Units:      []models.Unit{{Kind: models.Block, Index: 0}},
+			},
+			want: "r1c1 must be 8, since it is the only square in block 1 that can hold an 8.",
+		},
+		{
+			name: "no description",
+			step: models.Step{
+				Technique:    "Pointing Pair",
+				Cells:        []int{0, 1},
+				Eliminations: []models.Candidate{{Cell: 5, Digit: 3}, {Cell: 6, Digit: 3}},
+			},
+			want: "Look for a Pointing Pair at r1c1, r1c2. It shows that r1c6 cannot be 3, r1c7 cannot be 3.",
+		},
+		{
+			name: "subset",
+			step: models.Step{
+				Technique:    "Naked Pair",
+				Eliminations: []models.Candidate{{Cell: 0, Digit: 1}},
+				Description:  "Naked Pair {1,2} in row 1 at r1c4,r1c8 => r1c1<>1",
+			},
+			want: "Look for a Naked Pair: {1,2} in row 1 at r1c4,r1c8. It shows that r1c1 cannot be 1.",
+		},
+		{
+			name: "fish",
+			step: models.Step{
+				Technique:    "Finned X-Wing",
+				Eliminations: []models.Candidate{{Cell: 15, Digit: 4}, {Cell: 24, Digit: 4}},
+				Description: "Finned X-Wing: 4 in row 1, row 5 covered by column 2, column 7 " +
+					"with fins at r1c8 => r2c7<>4, r3c7<>4",
+			},
+			want: "Look for a Finned X-Wing: 4 in row 1, row 5 covered by column 2, column 7 " +
+				"with fins at r1c8. It shows that r2c7 cannot be 4, r3c7 cannot be 4.",
+		},
+		{
+			name: "wing",
+			step: models.Step{
+				Technique:    "XY-Wing",
+				Eliminations: []models.Candidate{{Cell: 40, Digit: 3}},
+				Description:  "XY-Wing: pivot r1c1, pincers r1c5,r5c1 => r5c5<>3",
+			},
+			want: "Look for an XY-Wing: pivot r1c1, pincers r1c5,r5c1. It shows that r5c5 cannot be 3.",
+		},
+		{
+			name: "chain",
+			step: models.Step{
+				Technique:    "X-Chain",
+				Eliminations: []models.Candidate{{Cell: 9, Digit: 5}},
+				Description:  "X-Chain: (5)r1c2=(5)r1c7-(5)r3c8=(5)r3c1 => r2c1<>5",
+			},
+			want: "Look for an X-Chain: (5)r1c2=(5)r1c7-(5)r3c8=(5)r3c1. It shows that r2c1 cannot be 5.",
+		},
+		{
+			name: "forcing chain",
+			step: models.Step{
+				Technique:    "Cell Forcing Chain",
+				Eliminations: []models.Candidate{{Cell: 47, Digit: 9}},
+				Proof: []models.Branch{
+					{Assumption: models.Candidate{Cell: 0, Digit: 1}, Steps: []models.Inference{
+						{Candidate: models.Candidate{Cell: 27, Digit: 9}, Placed: true},
+						{Candidate: models.Candidate{Cell: 47, Digit: 9}},
+					}},
+					{Assumption: models.Candidate{Cell: 0, Digit: 8}, Steps: []models.Inference{
+						{Candidate: models.Candidate{Cell: 47, Digit: 8}, Placed: true},
+						{Candidate: models.Candidate{Cell: 47, Digit: 9}},
+					}},
+				},
+				Description: "Cell Forcing Chain: r1c1 => r6c3<>9",
+			},
+			want: "Look for a Cell Forcing Chain: r1c1. It shows that r6c3 cannot be 9. " +
+				"If r1c1 must be 1, then r4c1 must be 9, r6c3 cannot be 9. " +
+				"If r1c1 must be 8, then r6c3 must be 8, r6c3 cannot be 9.",
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, explain(tc.step))
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package solver
+
+import (
+	"errors"
+	"fmt"
+
+	"mcconachie.co/sudoku/models"
+)
+
+// ErrStuck is returned by SolveSteps when none of the techniques can make
+// any more progress, and the grid is not yet solved.
+var ErrStuck = errors.New("no technique applies")
+
+// A Log records each step taken by SolveSteps, in order.
+// It can be encoded as JSON, and replayed onto the puzzle to reproduce
+// the solution.
+type Log struct {
+	// Puzzle holds the given values, one character per square,
+	// with '.' for the squares that are not given.
+	Puzzle string        `json:"puzzle"`
+	Steps  []models.Step `json:"steps"`
+	Solved bool          `json:"solved"`
+	// Solution holds the solved grid in the same form as Puzzle,
+	// if the techniques were enough to solve it.
+	Solution string `json:"solution,omitempty"`
+}
+
+// SolveSteps solves the given grid like a person would, by repeatedly
+// applying the simplest technique that makes progress, and records each
+// step. The techniques are taken from s.Registry, or from
+// models.DefaultRegistry if that is nil. The given grid is not modified.
+//
+// If the techniques get stuck, SolveSteps returns the steps taken so far,
+// along with ErrStuck. It never guesses.
+func (s *Solver) SolveSteps(g models.Grid) (Log, error) {
+	log := Log{Puzzle: puzzleString(g)}
+	grid := g.Clone()
+	if err := grid.Apply(givens(*grid)); err != nil {
+		return log, err
+	}
+
+	techniques := s.techniques()
+	for !isSolved(*grid) {
+		t, step, ok := nextStep(grid, techniques)
+		if !ok {
+			return log, ErrStuck
+		}
+		log.Steps = append(log.Steps, step)
+		if err := grid.Check(); err != nil {
+			return log, models.DetectedBy(err, t, step)
+		}
+	}
+	log.Solved, log.Solution = true, puzzleString(*grid)
+	return log, nil
+}
+
+// Replay applies the steps of the log to g, which should hold the puzzle
+// and nothing more, e.g. models.NewGrid([]byte(l.Puzzle)).
+// Returns an error if a step leaves the grid invalid.
+func (l Log) Replay(g models.Grid) error {
+	if err := g.Apply(givens(g)); err != nil {
+		return err
+	}
+	for i, step := range l.Steps {
+		if err := g.Apply(step); err != nil {
+			return fmt.Errorf("step %d (%s): %w", i+1, step.Technique, err)
+		}
+	}
+	return nil
+}
+
+// techniques returns the techniques for SolveSteps.
+func (s *Solver) techniques() []models.Technique {
+	r := s.Registry
+	if r == nil {
+		r = models.DefaultRegistry
+	}
+	if s.Limits == (models.Limits{}) {
+		return r.Techniques()
+	}
+	return r.TechniquesWith(s.Limits)
+}
+
+// nextStep applies the first of the techniques that makes progress,
+// returning the technique along with its step.
+func nextStep(g *models.Grid, techniques []models.Technique) (models.Technique, models.Step, bool) {
+	for _, t := range techniques {
+		if step, ok := t.Apply(g); ok {
+			return t, step, true
+		}
+	}
+	return nil, models.Step{}, false
+}
+
+// givens returns a step that places each defined square, removing its
+// value from its peers without going any further.
+func givens(g models.Grid) models.Step {
+	var s models.Step
+	for n := 0; n < 81; n++ {
+		if sq := g.Get(n); sq.IsDefined() {
+			s.Placements = append(s.Placements, models.Candidate{Cell: n, Digit: sq.Value()})
+		}
+	}
+	return s
+}
+
+// isSolved reports whether every square of the grid is defined.
+func isSolved(g models.Grid) bool {
+	_, done := findNextEmptyCell(&g)
+	return done
+}
+
+// puzzleString returns the defined squares of the grid, one character per
+// square, with '.' for the squares that are not defined.
+func puzzleString(g models.Grid) string {
+	b := make([]byte, 81)
+	for n := range b {
+		b[n] = g.Get(n).Display()
+	}
+	return string(b)
+}
@@ -0,0 +1,143 @@
+package solver
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcconachie.co/sudoku/models"
+)
+
+func TestSolveSteps(t *testing.T) {
+	var (
+		s   Solver
+		dlx = Solver{Engine: DancingLinks}
+	)
+	for i, puzzle := range loadPuzzles(t, 20) {
+		want, err := dlx.Solve(puzzle)
+		require.NoError(t, err)
+
+		log, err := s.SolveSteps(puzzle.AssumeUnique())
+		require.NoErrorf(t, err, "puzzle %d", i+1)
+		assert.True(t, log.Solved)
+		assert.Equal(t, puzzleString(want.Grid), log.Solution)
+		for _, step := range log.Steps {
+			assert.NotEmpty(t, step.Technique)
+			assert.Positive(t, step.Difficulty)
+			assert.NotEmpty(t, step.Description)
+		}
+
+		// the log survives a round trip through JSON, and replays to the
+		// same solution
+		data, err := json.Marshal(log)
+		require.NoError(t, err)
+		var decoded Log
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, log, decoded)
+
+		g := models.NewGrid([]byte(decoded.Puzzle))
+		require.NoError(t, decoded.Replay(g))
+		assert.Equal(t, log.Solution, puzzleString(g))
+	}
+}
+
+func TestSolveStepsStuck(t *testing.T) {
+	reg := models.NewRegistry()
+	for _, x := range models.BuiltinTechniques(models.BasicPasses) {
+		require.NoError(t, reg.Register(x))
+	}
+	s := Solver{Registry: reg}
+
+	// the ninth puzzle needs more than singles
+	puzzle := loadPuzzles(t, 9)[8]
+	log, err := s.SolveSteps(puzzle)
+	require.ErrorIs(t, err, ErrStuck)
+	assert.False(t, log.Solved)
+	assert.Empty(t, log.Solution)
+	assert.NotEmpty(t, log.Steps)
+
+	g := models.NewGrid([]byte(log.Puzzle))
+	require.NoError(t, log.Replay(g))
+	_, done := findNextEmptyCell(&g)
+	assert.False(t, done)
+}
+
+func TestSolverTechniques(t *testing.T) {
+	reg := models.NewRegistry()
+	for _, x := range models.BuiltinTechniques(models.BasicPasses) {
+		require.NoError(t, reg.Register(x))
+	}
+	require.NoError(t, reg.Register(idle{}))
+
+	limits := models.Limits{ChainDepth: 1}
+	for _, s := range []Solver{
+		{Registry: reg},
+		{Registry: reg, Limits: limits},
+	} {
+		assert.Equal(t, names(reg.Techniques()), names(s.techniques()), s.Limits)
+	}
+	s := Solver{Limits: limits}
+	assert.Equal(t, names(models.DefaultRegistry.Techniques()), names(s.techniques()))
+}
+
+// idle is a technique that never makes progress.
+type idle struct{}
+
+func (idle) Name() string                           { return "Idle" }
+func (idle) Difficulty() float64                    { return 1 }
+func (idle) Apply(*models.Grid) (models.Step, bool) { return models.Step{}, false }
+
+func names(ts []models.Technique) []string {
+	var out []string
+	for _, x := range ts {
+		out = append(out, x.Name())
+	}
+	return out
+}
+
+func TestSolveStepsNoSolution(t *testing.T) {
+	var s Solver
+	grid := models.NewGrid([]byte(`
+		123 456 78.
+		... ... ...
+		... ... ...
+
+		... ... ..9
+		... ... ...
+		... ... ...
+
+		... ... ...
+		... ... ...
+		... ... ...`))
+	_, err := s.SolveSteps(grid)
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrStuck)
+	var c *models.ContradictionError
+	assert.ErrorAs(t, err, &c)
+}
+
+func TestSolveStepsContradiction(t *testing.T) {
+	var s Solver
+	// a wrong value in r1c1 is only found out after some steps
+	grid := models.NewGrid([]byte(puzzleString(loadPuzzles(t, 1)[0])))
+	grid.Set(0, 7)
+	l, err := s.SolveSteps(grid)
+	require.NotEmpty(t, l.Steps)
+
+	// which technique finds it out depends on the order of the steps
+	var c *models.ContradictionError
+	if assert.ErrorAs(t, err, &c) {
+		assert.NotEmpty(t, c.Technique)
+		assert.NotZero(t, c.Pass)
+	}
+}
+
+func TestSolveStepsLeavesInputUnchanged(t *testing.T) {
+	var s Solver
+	in := cases_solve[1].in
+	grid := models.NewGrid([]byte(in))
+	_, err := s.SolveSteps(grid)
+	require.NoError(t, err)
+	assert.Equal(t, models.NewGrid([]byte(in)).String(), grid.String())
+}
@@ -0,0 +1,82 @@
+package solver
+
+import (
+	"errors"
+
+	"mcconachie.co/sudoku/models"
+)
+
+// ErrNotUnique is returned when a puzzle does not have exactly one
+// solution.
+var ErrNotUnique = errors.New("the puzzle does not have a unique solution")
+
+// Redundant returns the givens (defined squares) of a puzzle that could
+// each be removed on its own without losing the unique solution.
+// A puzzle with no redundant givens is minimal.
+// Returns ErrNotUnique if the puzzle does not have a unique solution.
+func (s *Solver) Redundant(g models.Grid) ([]int, error) {
+	c, puzzle := s.uniqueness(), givensOf(g)
+	if !c.CountSolutions(*puzzle).Unique() {
+		return nil, ErrNotUnique
+	}
+	var redundant []int
+	for ix := 0; ix < 81; ix++ {
+		sq := puzzle.Get(ix)
+		if !sq.IsDefined() {
+			continue
+		}
+		puzzle.Set(ix, 0)
+		if c.CountSolutions(*puzzle).Unique() {
+			redundant = append(redundant, ix)
+		}
+		puzzle.Set(ix, sq.Value())
+	}
+	return redundant, nil
+}
+
+// IsMinimal reports whether every given of a puzzle is needed for it to
+// have a unique solution.
+// Returns ErrNotUnique if the puzzle does not have a unique solution.
+func (s *Solver) IsMinimal(g models.Grid) (bool, error) {
+	redundant, err := s.Redundant(g)
+	return len(redundant) == 0 && err == nil, err
+}
+
+// Minimize returns a minimal puzzle with the same solution, by removing
+// givens one at a time, in order, for as long as the solution stays
+// unique. The given grid is not modified.
+// Returns ErrNotUnique if the puzzle does not have a unique solution.
+func (s *Solver) Minimize(g models.Grid) (models.Grid, error) {
+	c, puzzle := s.uniqueness(), givensOf(g)
+	if !c.CountSolutions(*puzzle).Unique() {
+		return models.Grid{}, ErrNotUnique
+	}
+	for ix := 0; ix < 81; ix++ {
+		sq := puzzle.Get(ix)
+		if !sq.IsDefined() {
+			continue
+		}
+		// a given that is needed now is still needed once others are gone,
+		// so a single pass is enough
+		puzzle.Set(ix, 0)
+		if !c.CountSolutions(*puzzle).Unique() {
+			puzzle.Set(ix, sq.Value())
+		}
+	}
+	return *puzzle, nil
+}
+
+// uniqueness returns a copy of the solver that stops counting as soon as
+// it knows whether a solution is unique.
+func (s *Solver) uniqueness() *Solver {
+	c := *s
+	c.Limit = 2
+	return &c
+}
+
+// givensOf returns a new grid holding just the defined squares of g, so
+// that no candidate depends on a given that is later removed.
+func givensOf(g models.Grid) *models.Grid {
+	puzzle := models.NewGrid([]byte(puzzleString(g)))
+	return &puzzle
+}
@@ -0,0 +1,83 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcconachie.co/sudoku/models"
+)
+
+func TestIsMinimal(t *testing.T) {
+	s := Solver{Engine: DancingLinks}
+	for i, puzzle := range loadPuzzles(t, 10) {
+		ok, err := s.IsMinimal(puzzle)
+		require.NoError(t, err)
+		assert.Truef(t, ok, "puzzle %d", i+1)
+	}
+}
+
+func TestMinimize(t *testing.T) {
+	for _, engine := range []Engine{Backtracking, DancingLinks} {
+		s := Solver{Engine: engine}
+		puzzle := loadPuzzles(t, 1)[0]
+		res, err := s.Solve(puzzle)
+		require.NoError(t, err)
+
+		// add some givens from the solution
+		padded := puzzle.Clone()
+		for _, ix := range []int{0, 40, 80} {
+			padded.Set(ix, res.Grid.Get(ix).Value())
+		}
+		before := padded.String()
+
+		redundant, err := s.Redundant(*padded)
+		require.NoError(t, err)
+		assert.Subset(t, redundant, []int{0, 40, 80}, engine.String())
+		ok, err := s.IsMinimal(*padded)
+		require.NoError(t, err)
+		assert.False(t, ok, engine.String())
+
+		// pencil marks make no difference, only the defined squares count
+		marked := padded.Clone()
+		var marks models.Step
+		keep := res.Grid.Get(1).Value()
+		for d := 1; d <= 9; d++ {
+			if d != keep && d != keep%9+1 {
+				marks.Eliminations = append(marks.Eliminations, models.Candidate{Cell: 1, Digit: d})
+			}
+		}
+		require.NoError(t, marked.Apply(marks))
+		again, err := s.Redundant(*marked)
+		require.NoError(t, err)
+		assert.Equal(t, redundant, again, engine.String())
+
+		minimal, err := s.Minimize(*padded)
+		require.NoError(t, err)
+		assert.Equal(t, before, padded.String(), engine.String())
+		ok, err = s.IsMinimal(minimal)
+		require.NoError(t, err)
+		assert.True(t, ok, engine.String())
+		for ix := 0; ix < 81; ix++ {
+			if sq := minimal.Get(ix); sq.IsDefined() {
+				assert.Equal(t, padded.Get(ix), sq, models.CellName(ix))
+			}
+		}
+		sols := s.CountSolutions(minimal)
+		require.True(t, sols.Unique(), engine.String())
+		assert.Equal(t, res.Grid.String(), sols.Grids[0].String(), engine.String())
+	}
+}
+
+func TestMinimalNotUnique(t *testing.T) {
+	var s Solver
+	g := models.NewGrid([]byte("123456789" + strings.Repeat(".", 72)))
+
+	_, err := s.Redundant(g)
+	assert.ErrorIs(t, err, ErrNotUnique)
+	_, err = s.IsMinimal(g)
+	assert.ErrorIs(t, err, ErrNotUnique)
+	_, err = s.Minimize(g)
+	assert.ErrorIs(t, err, ErrNotUnique)
+}
@@ -0,0 +1,46 @@
+package solver
+
+import (
+	"bufio"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"mcconachie.co/sudoku/models"
+)
+
+// loadPuzzles reads the first n puzzles from the 17 clue collection.
+func loadPuzzles(t testing.TB, n int) []models.Grid {
+	t.Helper()
+	f, err := os.Open("../all_17_clue_sudokus.txt")
+	require.NoError(t, err)
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	s.Scan() // discard count of puzzles
+
+	grids := make([]models.Grid, 0, n)
+	for len(grids) < n && s.Scan() {
+		grids = append(grids, models.NewGrid(s.Bytes()))
+	}
+	require.NoError(t, s.Err())
+	return grids
+}
+
+// TestPassesAreSound checks that the propagation passes never remove the
+// value that a square holds in the (unique) solution.
+func TestPassesAreSound(t *testing.T) {
+	solv := Solver{Engine: DancingLinks}
+	for i, puzzle := range loadPuzzles(t, 200) {
+		res, err := solv.Solve(puzzle)
+		require.NoError(t, err)
+
+		g := puzzle.AssumeUnique().Clone()
+		require.NoErrorf(t, g.NormalizeWith(models.AllPasses), "puzzle %d", i+1)
+		for n := 0; n < 81; n++ {
+			want := res.Grid.Get(n)
+			require.Equalf(t, want, g.Get(n)&want,
+				"puzzle %d: square %s lost its solution", i+1, models.CellName(n))
+		}
+	}
+}
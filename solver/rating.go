@@ -0,0 +1,114 @@
+package solver
+
+import (
+	"errors"
+	"strconv"
+
+	"mcconachie.co/sudoku/models"
+)
+
+// A Rating describes how hard a puzzle is to solve by logic alone, using
+// the same measures as Sudoku Explainer.
+type Rating struct {
+	// ER is the difficulty of the hardest step needed to solve the puzzle.
+	ER float64 `json:"er"`
+	// EP is the difficulty of the hardest step needed to place the first
+	// value.
+	EP float64 `json:"ep"`
+	// ED is the difficulty of the first step.
+	ED float64 `json:"ed"`
+	// Solved is false if the techniques were not enough to solve the
+	// puzzle. The measures then only cover the steps that were taken.
+	Solved bool `json:"solved"`
+}
+
+// String implements the fmt.Stringer interface, e.g. "ER=2.6/EP=1.2/ED=1.2"
+func (r Rating) String() string {
+	return "ER=" + formatDifficulty(r.ER) +
+		"/EP=" + formatDifficulty(r.EP) +
+		"/ED=" + formatDifficulty(r.ED)
+}
+
+func formatDifficulty(d float64) string {
+	return strconv.FormatFloat(d, 'f', 1, 64)
+}
+
+// Tier returns the difficulty tier of the puzzle, based on its ER.
+// A puzzle that could not be solved by logic alone is Diabolical.
+func (r Rating) Tier() Tier {
+	switch {
+	case !r.Solved:
+		return Diabolical
+	case r.ER <= 1.5:
+		return Easy
+	case r.ER <= 2.8:
+		return Medium
+	case r.ER <= 4.6:
+		return Hard
+	default:
+		return Diabolical
+	}
+}
+
+// A Tier is a broad band of difficulty.
+type Tier int
+
+const (
+	// Easy puzzles only need hidden singles (ER 1.5 or less).
+	Easy Tier = iota
+	// Medium puzzles also need naked singles and locked candidates
+	// (ER 2.8 or less).
+	Medium
+	// Hard puzzles also need subsets, basic fish, wings, the simpler
+	// single digit patterns and unique rectangles (ER 4.6 or less).
+	Hard
+	// Diabolical puzzles need chains, almost locked sets, forcing chains
+	// or other advanced techniques, or cannot be solved by logic alone.
+	Diabolical
+)
+
+// String implements the fmt.Stringer interface
+func (t Tier) String() string {
+	switch t {
+	case Easy:
+		return "easy"
+	case Medium:
+		return "medium"
+	case Hard:
+		return "hard"
+	case Diabolical:
+		return "diabolical"
+	default:
+		return "unknown tier"
+	}
+}
+
+// Rate solves the given grid with SolveSteps, and rates the steps taken.
+// The given grid is not modified.
+// Returns an error if the grid is invalid.
+func (s *Solver) Rate(g models.Grid) (Rating, error) {
+	l, err := s.SolveSteps(g)
+	if err != nil && !errors.Is(err, ErrStuck) {
+		return Rating{}, err
+	}
+	return l.Rating(), nil
+}
+
+// Rating rates the steps of the log.
+func (l Log) Rating() Rating {
+	r := Rating{Solved: l.Solved}
+	placed := false
+	for i, step := range l.Steps {
+		if i == 0 {
+			r.ED = step.Difficulty
+		}
+		if step.Difficulty > r.ER {
+			r.ER = step.Difficulty
+		}
+		if !placed {
+			r.EP = r.ER
+			placed = len(step.Placements) > 0
+		}
+	}
+	return r
+}
@@ -0,0 +1,104 @@
+package solver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcconachie.co/sudoku/models"
+)
+
+func TestLogRating(t *testing.T) {
+	var (
+		place = func(d float64) models.Step {
+			return models.Step{Difficulty: d, Placements: []models.Candidate{{Cell: 0, Digit: 1}}}
+		}
+		elim = func(d float64) models.Step {
+			return models.Step{Difficulty: d, Eliminations: []models.Candidate{{Cell: 0, Digit: 1}}}
+		}
+	)
+	tt := []struct {
+		name string
+		log  Log
+		want Rating
+	}{
+		{
+			name: "no steps",
+			log:  Log{Solved: true},
+			want: Rating{Solved: true},
+		},
+		{
+			name: "singles",
+			log:  Log{Steps: []models.Step{place(1.2), place(1.5), place(1.2)}, Solved: true},
+			want: Rating{ER: 1.5, EP: 1.2, ED: 1.2, Solved: true},
+		},
+		{
+			name: "eliminations before the first placement",
+			log: Log{
+				Steps:  []models.Step{elim(2.6), elim(3.2), elim(2.8), place(2.3), elim(4.2), place(1.2)},
+				Solved: true,
+			},
+			want: Rating{ER: 4.2, EP: 3.2, ED: 2.6, Solved: true},
+		},
+		{
+			name: "stuck",
+			log:  Log{Steps: []models.Step{place(1.2), elim(2.6)}},
+			want: Rating{ER: 2.6, EP: 1.2, ED: 1.2},
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.want, tc.log.Rating())
+		})
+	}
+}
+
+func TestRatingTier(t *testing.T) {
+	a := assert.New(t)
+	a.Equal(Easy, Rating{ER: 1.5, Solved: true}.Tier())
+	a.Equal(Medium, Rating{ER: 2.3, Solved: true}.Tier())
+	a.Equal(Medium, Rating{ER: 2.8, Solved: true}.Tier())
+	a.Equal(Hard, Rating{ER: 3.2, Solved: true}.Tier())
+	a.Equal(Diabolical, Rating{ER: 7.0, Solved: true}.Tier())
+	a.Equal(Diabolical, Rating{ER: 1.2}.Tier())
+	a.Equal("ER=4.2/EP=1.2/ED=1.2", Rating{ER: 4.2, EP: 1.2, ED: 1.2}.String())
+}
+
+func TestRate(t *testing.T) {
+	puzzles := loadPuzzles(t, 21)
+	tt := []struct {
+		puzzle int
+		tier   Tier
+	}{
+		{puzzle: 3, tier: Easy},
+		{puzzle: 1, tier: Medium},
+		{puzzle: 21, tier: Hard},
+		{puzzle: 9, tier: Diabolical},
+	}
+
+	var s Solver
+	for _, tc := range tt {
+		r, err := s.Rate(puzzles[tc.puzzle-1].AssumeUnique())
+		require.NoError(t, err)
+		t.Log(tc.puzzle, r)
+		assert.True(t, r.Solved, tc.puzzle)
+		assert.Equal(t, tc.tier, r.Tier(), tc.puzzle)
+		assert.LessOrEqual(t, r.ED, r.EP, tc.puzzle)
+		assert.LessOrEqual(t, r.EP, r.ER, tc.puzzle)
+	}
+}
+
+func TestRateStuck(t *testing.T) {
+	reg := models.NewRegistry()
+	for _, x := range models.BuiltinTechniques(models.BasicPasses) {
+		require.NoError(t, reg.Register(x))
+	}
+	s := Solver{Registry: reg}
+	r, err := s.Rate(loadPuzzles(t, 9)[8])
+	require.NoError(t, err)
+	assert.False(t, r.Solved)
+	assert.Equal(t, Diabolical, r.Tier())
+}
@@ -0,0 +1,160 @@
+// Package solver finds solutions to sudoku grids using the propagation
+// rules of the models package, falling back to trial and error when logic
+// alone is not enough.
+package solver
+
+import (
+	"errors"
+	"time"
+
+	"mcconachie.co/sudoku/models"
+)
+
+// ErrNoSolution is returned when a grid cannot be solved.
+var ErrNoSolution = errors.New("no solution")
+
+// A Solver solves sudoku grids.
+// The zero value is ready to use.
+type Solver struct {
+	// Engine selects the search algorithm. The zero value is Backtracking.
+	Engine Engine
+	// Passes selects the propagation passes used by the Backtracking
+	// engine before each guess. If zero, models.BasicPasses are used.
+	Passes models.Pass
+	// Limit is the number of solutions after which CountSolutions stops
+	// searching. If zero, DefaultLimit is used.
+	Limit int
+	// Registry selects the techniques used by SolveSteps. If nil,
+	// models.DefaultRegistry is used.
+	Registry *models.Registry
+	// ForcingHints lets Hint suggest forcing chains and nets. They amount
+	// to trial and error, so hints leave them out by default.
+	ForcingHints bool
+	// Limits bounds the searches of the built-in techniques, including
+	// those in the Registry. The zero value gives the default limits.
+	Limits models.Limits
+}
+
+// An Engine is a search algorithm that a Solver can use.
+type Engine int
+
+const (
+	// Backtracking propagates constraints using models.Grid.Normalize,
+	// and guesses the value of the first undefined square when stuck.
+	Backtracking Engine = iota
+	// DancingLinks treats the grid as an exact cover problem, and solves
+	// it with Knuth's Algorithm X.
+	DancingLinks
+)
+
+// String implements the fmt.Stringer interface
+func (e Engine) String() string {
+	switch e {
+	case Backtracking:
+		return "backtracking"
+	case DancingLinks:
+		return "dancing links"
+	default:
+		return "unknown engine"
+	}
+}
+
+// Result describes a solved grid, along with some statistics about
+// the search that was needed to solve it.
+type Result struct {
+	// Grid is the solved grid.
+	Grid models.Grid
+	// Backtracks is the number of guesses that had to be undone.
+	Backtracks int
+	// Nodes is the number of search nodes visited, including the root.
+	Nodes int
+	// Elapsed is the wall clock time taken to solve the grid.
+	Elapsed time.Duration
+}
+
+// Solve finds a solution for the given grid.
+// The given grid is not modified.
+// Returns ErrNoSolution if the grid cannot be solved.
+func (s *Solver) Solve(g models.Grid) (Result, error) {
+	start := time.Now()
+
+	var (
+		res  Result
+		done bool
+	)
+	switch s.Engine {
+	case DancingLinks:
+		res.Grid, res.Backtracks, res.Nodes, done = s.solveDLX(g)
+	default:
+		st := search{techniques: models.BuiltinTechniquesWith(s.passes(), s.Limits)}
+		grid := g.Clone()
+		done = st.solve(grid)
+		res.Grid, res.Backtracks, res.Nodes = *grid, st.backtracks, st.nodes
+	}
+
+	res.Elapsed = time.Since(start)
+	if !done {
+		return Result{
+			Backtracks: res.Backtracks,
+			Nodes:      res.Nodes,
+			Elapsed:    res.Elapsed,
+		}, ErrNoSolution
+	}
+	return res, nil
+}
+
+// passes returns the propagation passes for the Backtracking engine.
+func (s *Solver) passes() models.Pass {
+	if s.Passes == 0 {
+		return models.BasicPasses
+	}
+	return s.Passes
+}
+
+// search holds the statistics for a single call to Solve.
+type search struct {
+	techniques []models.Technique
+	backtracks int
+	nodes      int
+}
+
+// solve recursively solves a sudoku grid, returning true when it is solved.
+func (st *search) solve(g *models.Grid) bool {
+	st.nodes++
+	if err := g.Propagate(st.techniques); err != nil {
+		return false
+	}
+	ix, done := findNextEmptyCell(g)
+	if done {
+		return true
+	}
+
+	for k := 1; k <= 9; k++ {
+		if !g.CanSet(ix, k) {
+			continue
+		}
+		snapshot := g.Clone()
+		g.Set(ix, k)
+		if st.solve(g) {
+			return true
+		}
+		*g = *snapshot
+		st.backtracks++
+	}
+
+	return false
+}
+
+// findNextEmptyCell returns the index of the first square that is not yet
+// defined, or true if every square is defined.
+func findNextEmptyCell(g *models.Grid) (int, bool) {
+	ix := 0
+	for ix < 81 {
+		sq := g.Get(ix)
+		if !sq.IsDefined() {
+			return ix, false
+		}
+		ix++
+	}
+	return 0, true
+}
@@ -0,0 +1,19 @@
+package solver
+
+import "mcconachie.co/sudoku/models"
+
+// Validate reports every mistake in g, a partly solved copy of the given
+// puzzle: see models.Grid.Validate. It also lists the defined squares of g
+// that differ from the unique solution of the puzzle. Neither grid is
+// modified, and g is not solved.
+// Returns ErrNotUnique, along with the other mistakes, if the puzzle does
+// not have a unique solution.
+func (s *Solver) Validate(puzzle, g models.Grid) (models.Validation, error) {
+	v := g.Validate()
+	sol := s.uniqueness().CountSolutions(*givensOf(puzzle))
+	if !sol.Unique() {
+		return v, ErrNotUnique
+	}
+	v.Wrong = g.Differences(sol.Grids[0])
+	return v, nil
+}
@@ -0,0 +1,52 @@
+package solver
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"mcconachie.co/sudoku/models"
+)
+
+func TestValidate(t *testing.T) {
+	for _, engine := range []Engine{Backtracking, DancingLinks} {
+		s := Solver{Engine: engine}
+		puzzle := loadPuzzles(t, 1)[0]
+		res, err := s.Solve(puzzle)
+		require.NoError(t, err)
+
+		// fill in a few squares, one of them wrongly
+		var empty []int
+		for ix := 0; ix < 81 && len(empty) < 3; ix++ {
+			if !puzzle.Get(ix).IsDefined() {
+				empty = append(empty, ix)
+			}
+		}
+		g := models.NewGrid([]byte(puzzleString(puzzle)))
+		g.Set(empty[0], res.Grid.Get(empty[0]).Value())
+		g.Set(empty[1], res.Grid.Get(empty[1]).Value())
+		g.Set(empty[2], res.Grid.Get(empty[2]).Value()%9+1)
+		before := g.String()
+
+		v, err := s.Validate(puzzle, g)
+		require.NoError(t, err, engine.String())
+		assert.Equal(t, []int{empty[2]}, v.Wrong, engine.String())
+		assert.True(t, v.Diverges(), engine.String())
+		assert.Equal(t, before, g.String(), "the grid must not be changed")
+
+		v, err = s.Validate(puzzle, res.Grid)
+		require.NoError(t, err, engine.String())
+		assert.True(t, v.Valid(), engine.String())
+	}
+}
+
+func TestValidateNotUnique(t *testing.T) {
+	var s Solver
+	puzzle := models.NewGrid([]byte(strings.Repeat(".", 81)))
+	g := models.NewGrid([]byte("55"))
+	v, err := s.Validate(puzzle, g)
+	assert.ErrorIs(t, err, ErrNotUnique)
+	assert.Len(t, v.Duplicates, 2, "row 1 and block 1")
+	assert.Empty(t, v.Wrong)
+}
@@ -2,18 +2,30 @@ package main
 
 import (
 	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
 	"mcconachie.co/sudoku/models"
+	"mcconachie.co/sudoku/solver"
 )
 
 func main() {
+	useDLX := flag.Bool("dlx", false, "solve using dancing links instead of backtracking")
+	steps := flag.Bool("steps", false, "solve using logic alone, and print a JSON solve log for each puzzle")
+	rate := flag.Bool("rate", false, "rate each puzzle on the Sudoku Explainer scale, and count the puzzles in each tier")
+	minimal := flag.Bool("minimal", false, "check that every given of each puzzle is needed for a unique solution")
+	strict := flag.Bool("strict", false, "reject puzzles with invalid characters or the wrong number of cells")
+	flag.Parse()
+
 	start := time.Now()
 
-	infile := os.Args[1]
+	infile := flag.Arg(0)
 	f, err := os.Open(infile)
 	if err != nil {
 		log.Fatal(err)
@@ -24,13 +36,63 @@ func main() {
 	// discard the first line (number of puzzles)
 	s.Scan()
 
+	var solv solver.Solver
+	if *useDLX {
+		solv.Engine = solver.DancingLinks
+	}
+	out := json.NewEncoder(os.Stdout)
+	tiers := make(map[solver.Tier]int)
+	// counting solutions is much faster with dancing links
+	counter := solver.Solver{Engine: solver.DancingLinks}
+	notMinimal := 0
 	i := 0
 	for s.Scan() {
 		if err := s.Err(); err != nil {
 			log.Fatal(fmt.Errorf("error reading input: %w", err))
 		}
-		sudoku := models.NewGrid(s.Bytes())
-		solve(&sudoku)
+		var sudoku models.Grid
+		if *strict {
+			if sudoku, err = models.ParseGrid(s.Bytes()); err != nil {
+				log.Fatal(fmt.Errorf("puzzle %d: %w", i+1, err))
+			}
+		} else {
+			sudoku = models.NewGrid(s.Bytes())
+		}
+		switch {
+		case *steps:
+			// puzzles in a collection are expected to have a unique solution
+			l, err := solv.SolveSteps(sudoku.AssumeUnique())
+			if err != nil && !errors.Is(err, solver.ErrStuck) {
+				log.Fatal(fmt.Errorf("puzzle %d: %w", i+1, err))
+			}
+			if err := out.Encode(l); err != nil {
+				log.Fatal(err)
+			}
+		case *rate:
+			r, err := solv.Rate(sudoku.AssumeUnique())
+			if err != nil {
+				log.Fatal(fmt.Errorf("puzzle %d: %w", i+1, err))
+			}
+			tiers[r.Tier()]++
+			fmt.Printf("%s %s %s\n", s.Text(), r, r.Tier())
+		case *minimal:
+			redundant, err := counter.Redundant(sudoku)
+			if err != nil {
+				log.Fatal(fmt.Errorf("puzzle %d: %w", i+1, err))
+			}
+			if len(redundant) > 0 {
+				notMinimal++
+				names := make([]string, len(redundant))
+				for k, ix := range redundant {
+					names[k] = models.CellName(ix)
+				}
+				fmt.Printf("puzzle %d: redundant givens at %s\n", i+1, strings.Join(names, ","))
+			}
+		default:
+			if _, err := solv.Solve(sudoku); err != nil {
+				log.Fatal(fmt.Errorf("puzzle %d: %w", i+1, err))
+			}
+		}
 		i++
 	}
 
@@ -39,47 +101,18 @@ func main() {
 	}
 
 	duration := time.Since(start)
-	fmt.Printf("solved %d sudokus in %s\n", i, duration)
-}
-
-// solve recursively solves a sudoku grid, returning true when it is solved,
-// along with the number of times we had to backtrack.
-func solve(g *models.Grid) (bool, int) {
-	if err := g.Normalize(); err != nil {
-		return false, 0
+	summary := os.Stdout
+	if *steps {
+		// keep stdout for the solve logs
+		summary = os.Stderr
 	}
-	ix, done := findNextEmptyCell(g)
-	if done {
-		return true, 0
+	fmt.Fprintf(summary, "solved %d sudokus in %s\n", i, duration)
+	if *minimal {
+		fmt.Printf("%d of %d puzzles are minimal\n", i-notMinimal, i)
 	}
-
-	backtracks := 0
-	for k := 1; k <= 9; k++ {
-		if !g.CanSet(ix, k) {
-			continue
-		}
-		snapshot := g.Clone()
-		g.Set(ix, k)
-		done, b := solve(g)
-		backtracks += b
-		if done {
-			return true, backtracks
-		}
-		*g = *snapshot
-		backtracks++
-	}
-
-	return false, backtracks
-}
-
-func findNextEmptyCell(g *models.Grid) (int, bool) {
-	ix := 0
-	for ix < 81 {
-		sq := g.Get(ix)
-		if !sq.IsDefined() {
-			return ix, false
+	if *rate {
+		for t := solver.Easy; t <= solver.Diabolical; t++ {
+			fmt.Printf("%s: %d\n", t, tiers[t])
 		}
-		ix++
 	}
-	return 0, true
 }